@@ -13,6 +13,80 @@ export interface Quote {
 export interface Indicator {
     date: string; // Преобразуем time.Time в строку в формате ISO
     value: number;
+    conditions?: string[]; // сработавшие условия сигнала
+    score?: number;        // суммарный вес сработавших условий
+    explanation?: SignalExplanation;
+}
+
+// разбор условий на баре, также из GET /rsi/explain
+export interface SignalExplanation {
+    date: string;
+    side: 'buy' | 'sell';
+    signal: boolean;
+    nearMiss: boolean;
+    score: number;
+    threshold: number;
+    values: Record<string, number>;
+    levels: Record<string, number>;
+    conditions: { name: string; enabled: boolean; weight: number; passed: boolean }[];
+}
+
+// series из ответов API: значения выровнены по quote.date, null - прогрев индикатора
+export type IndicatorSeries = Record<string, (number | null)[]>;
+
+// levels из ответов API: пороговые уровни индикаторов
+export type IndicatorLevels = Record<string, number>;
+
+// сделка из POST /rsi/evaluate (trades), также GET /rsi/trades и /rsi/trades.csv
+export interface BacktestTrade {
+    side: 'long' | 'short';
+    entryTime: string;
+    exitTime: string;
+    entryPrice: number;
+    exitPrice: number;
+    quantity: number;
+    profit: number;
+    profitPercent: number;
+    costs: number;
+    bars: number;
+    maePercent: number;
+    mfePercent: number;
+    exitReason: 'signal' | 'stopLoss' | 'takeProfit' | 'trailingStop' | 'timeExit' | 'endOfData';
+}
+
+// metrics из currentOpti и optimization; неопределённые коэффициенты равны 0
+export interface BacktestMetrics {
+    sharpe: number;
+    sortino: number;
+    calmar: number;
+    profitFactor: number;
+    expectancy: number;
+    averageWin: number;
+    averageLoss: number;
+    maxConsecutiveLosses: number;
+    exposurePercent: number;
+    maxDrawdownPercent: number;
+    maxDrawdownBars: number;
+    cagr: number;
+    recoveryFactor: number;
+}
+
+// описание индикатора из GET /indicators
+export interface IndicatorInfo {
+    name: string;
+    title: string;
+    pane: 'price' | 'oscillator';
+    params: { name: string; default: number; min: number; integer: boolean }[];
+    outputs: string[];
+}
+
+// индикатор из POST /indicators/overlay, ряды выровнены по dates ответа
+export interface IndicatorOverlay {
+    id: string;
+    name: string;
+    pane: 'price' | 'oscillator';
+    params: Record<string, number>;
+    series: IndicatorSeries;
 }
 
 
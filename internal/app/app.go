@@ -1,7 +1,13 @@
 package app
 
 import (
+	"context"
 	"main/internal/feeder"
+	"main/internal/quality"
+	"main/internal/resample"
+	"main/internal/utils"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/markcheno/go-quote"
@@ -19,23 +25,198 @@ type Indicator struct {
 	Value float64   `json:"value"`
 }
 
+// Selection - инструмент, источник, диапазон дат и период, с которыми работают стратегии.
+type Selection struct {
+	Symbol         string       `json:"symbol"`
+	StartDate      string       `json:"start_date"`
+	EndDate        string       `json:"end_date"`
+	IntervalString string       `json:"interval"`
+	Interval       quote.Period `json:"-"`
+	Source         string       `json:"source"` // имя источника, пусто - по умолчанию
+	// Version растёт при каждом Select: результаты, посчитанные на прежней версии, устарели
+	Version uint64 `json:"-"`
+}
+
 type App struct {
-	Symbol         string                                  `json:"symbol"`
-	StartDate      string                                  `json:"start_date"`
-	EndDate        string                                  `json:"end_date"`
-	IntervalString string                                  `json:"interval"`
-	Interval       quote.Period                            `json:"-"`
-	Quote          map[string]map[quote.Period]quote.Quote `json:"-"`
-	Feeder         feeder.Feeder
+	Selection
+	Quote         map[string]map[quote.Period]quote.Quote `json:"-"`
+	Feeders       *feeder.Registry                        `json:"-"`
+	QualityPolicy quality.Policy                          `json:"-"`
+
+	// диапазоны дат, для которых загружены котировки в Quote
+	loaded map[string]map[quote.Period]dateRange
+
+	// mu защищает Selection, Quote и loaded. Она берётся только на чтение и замену
+	// этих полей: загрузка котировок и расчёты стратегий идут без неё. Массивы
+	// котировок в Quote не изменяются после сохранения, их можно читать без блокировки.
+	mu sync.Mutex
+}
+
+type dateRange struct {
+	source     string
+	start, end time.Time
 }
 
-func NewApp(feeder feeder.Feeder) *App {
+// covers сообщает, содержит ли диапазон r диапазон other того же источника.
+// Нулевая граница означает отсутствие ограничения.
+func (r dateRange) covers(other dateRange) bool {
+	if r.source != other.source {
+		return false
+	}
+	startOK := r.start.IsZero() || (!other.start.IsZero() && !other.start.Before(r.start))
+	endOK := r.end.IsZero() || (!other.end.IsZero() && !other.end.After(r.end))
+	return startOK && endOK
+}
+
+func NewApp(feeders *feeder.Registry) *App {
 	return &App{
-		Quote:     make(map[string]map[quote.Period]quote.Quote),
-		Symbol:    SymbolDefault,
-		StartDate: StartDateDefault,
-		EndDate:   EndDateDefault,
-		Interval:  IntervalDefault,
-		Feeder:    feeder,
+		Quote: make(map[string]map[quote.Period]quote.Quote),
+		Selection: Selection{
+			Symbol:    SymbolDefault,
+			StartDate: StartDateDefault,
+			EndDate:   EndDateDefault,
+			Interval:  IntervalDefault,
+		},
+		Feeders:       feeders,
+		QualityPolicy: quality.DefaultPolicy(),
+		loaded:        make(map[string]map[quote.Period]dateRange),
+	}
+}
+
+// Current возвращает текущий выбор и котировку для него; ok=false, если она не загружена.
+func (a *App) Current() (sel Selection, q quote.Quote, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	q, ok = a.Quote[a.Symbol][a.Interval]
+	return a.Selection, q, ok
+}
+
+// Select делает sel текущим выбором с новой версией. Котировку для него загружает LoadQuote.
+func (a *App) Select(sel Selection) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	sel.Version = a.Version + 1
+	a.Selection = sel
+}
+
+// QuoteFor возвращает загруженную котировку symbol/period.
+func (a *App) QuoteFor(symbol string, period quote.Period) (quote.Quote, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	q, ok := a.Quote[symbol][period]
+	return q, ok
+}
+
+// LoadQuote возвращает котировки symbol из источника source за период и сохраняет их в Quote.
+// Если в кэше есть более мелкие бары за нужный диапазон, старший период строится
+// из них без обращения к Feeder. Диапазон, доходящий до ещё не закрытого бара, всегда
+// загружается заново. Новые данные проверяются и исправляются по QualityPolicy.
+func (a *App) LoadQuote(ctx context.Context, source, symbol, startDate, endDate string, period quote.Period) (quote.Quote, quality.Report, error) {
+	src, err := a.Feeders.Get(source)
+	if err != nil {
+		return quote.Quote{}, quality.Report{}, err
+	}
+	start, end, err := utils.ParseDateRange(startDate, endDate, time.UTC)
+	if err != nil {
+		return quote.Quote{}, quality.Report{}, err
+	}
+	want := dateRange{source: src.Name, start: start, end: end}
+
+	var q quote.Quote
+	ok := false
+	if !reachesOpenBar(end, period) {
+		a.mu.Lock()
+		q, ok = a.fromCache(symbol, period, want)
+		a.mu.Unlock()
+	}
+	// источник опрашивается без блокировки: медленный запрос не должен держать остальные
+	if !ok {
+		q, err = src.Feeder.GetQuote(ctx, symbol, startDate, endDate, period)
+		if err != nil {
+			return quote.Quote{}, quality.Report{}, err
+		}
+	}
+	q, report, err := quality.Check(q, period, a.QualityPolicy)
+	if err != nil {
+		return quote.Quote{}, quality.Report{}, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.Quote[symbol] == nil {
+		a.Quote[symbol] = make(map[quote.Period]quote.Quote)
+	}
+	if a.loaded[symbol] == nil {
+		a.loaded[symbol] = make(map[quote.Period]dateRange)
+	}
+	a.Quote[symbol][period] = q
+	a.loaded[symbol][period] = want
+	return q, report, nil
+}
+
+// fromCache ищет в кэше период, из которого можно получить period за диапазон want.
+// Вызывается под mu.
+func (a *App) fromCache(symbol string, period quote.Period, want dateRange) (quote.Quote, bool) {
+	var best quote.Period
+	found := false
+	for p, r := range a.loaded[symbol] {
+		if !r.covers(want) || (p != period && !resample.CanResample(utils.PeriodDuration(p), period)) {
+			continue
+		}
+		// чем крупнее исходный период, тем меньше баров агрегировать
+		if !found || utils.PeriodDuration(p) > utils.PeriodDuration(best) {
+			best, found = p, true
+		}
+	}
+	if !found {
+		return quote.Quote{}, false
+	}
+
+	q := utils.SliceQuote(a.Quote[symbol][best], want.start, want.end)
+	if best == period {
+		return q, true
+	}
+	q, err := resample.Resample(q, period, resample.Options{Base: utils.PeriodDuration(best)})
+	if err != nil {
+		return quote.Quote{}, false
+	}
+	return q, true
+}
+
+// reachesOpenBar сообщает, захватывает ли диапазон с концом end текущий незакрытый бар:
+// в кэше он мог остаться неполным, а новые бары в кэш ещё не попали.
+func reachesOpenBar(end time.Time, period quote.Period) bool {
+	return end.IsZero() || end.After(resample.BarStart(time.Now(), period))
+}
+
+// AppendCandle добавляет свечу из потока в Quote[c.Symbol][c.Period] или обновляет
+// последний бар с той же датой и возвращает котировку. Свечи старше последнего бара
+// игнорируются.
+func (a *App) AppendCandle(c feeder.Candle) quote.Quote {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.Quote[c.Symbol] == nil {
+		a.Quote[c.Symbol] = make(map[quote.Period]quote.Quote)
+	}
+	q := a.Quote[c.Symbol][c.Period]
+	q.Symbol = c.Symbol
+
+	n := len(q.Date)
+	switch {
+	case n > 0 && c.Date.Equal(q.Date[n-1]):
+		// копия: прежние массивы могут читать расчёты, идущие без блокировки
+		q.Open, q.High, q.Low = slices.Clone(q.Open), slices.Clone(q.High), slices.Clone(q.Low)
+		q.Close, q.Volume = slices.Clone(q.Close), slices.Clone(q.Volume)
+		q.Open[n-1], q.High[n-1], q.Low[n-1] = c.Open, c.High, c.Low
+		q.Close[n-1], q.Volume[n-1] = c.Close, c.Volume
+	case n == 0 || c.Date.After(q.Date[n-1]):
+		q.Date = append(q.Date, c.Date)
+		q.Open = append(q.Open, c.Open)
+		q.High = append(q.High, c.High)
+		q.Low = append(q.Low, c.Low)
+		q.Close = append(q.Close, c.Close)
+		q.Volume = append(q.Volume, c.Volume)
 	}
+	a.Quote[c.Symbol][c.Period] = q
+	return q
 }
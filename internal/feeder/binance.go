@@ -0,0 +1,141 @@
+package feeder
+
+import (
+	"context"
+	"fmt"
+	"main/internal/utils"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+const (
+	BinanceBaseURL = "https://api.binance.com"
+	binanceMaxBars = 1000
+)
+
+// binanceIntervals - соответствие периодов go-quote интервалам klines Binance.
+var binanceIntervals = map[quote.Period]string{
+	quote.Min1:    "1m",
+	quote.Min3:    "3m",
+	quote.Min5:    "5m",
+	quote.Min15:   "15m",
+	quote.Min30:   "30m",
+	quote.Min60:   "1h",
+	quote.Hour2:   "2h",
+	quote.Hour4:   "4h",
+	quote.Hour6:   "6h",
+	quote.Hour8:   "8h",
+	quote.Hour12:  "12h",
+	quote.Daily:   "1d",
+	quote.Day3:    "3d",
+	quote.Weekly:  "1w",
+	quote.Monthly: "1M",
+}
+
+// FeederBinance загружает свечи из Binance-совместимого API /api/v3/klines.
+type FeederBinance struct {
+	http *httpClient
+}
+
+// NewFeederBinance создаёт фидер Binance, пустой baseURL - публичный API.
+func NewFeederBinance(baseURL string) *FeederBinance {
+	if baseURL == "" {
+		baseURL = BinanceBaseURL
+	}
+	return &FeederBinance{http: newHTTPClient(baseURL)}
+}
+
+func (f *FeederBinance) GetQuote(ctx context.Context, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
+	start, end, err := utils.ParseDateRange(startDate, endDate, time.UTC)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+	if start.IsZero() {
+		return quote.Quote{}, fmt.Errorf("start date is required")
+	}
+	if end.IsZero() || end.After(time.Now()) {
+		end = time.Now()
+	}
+	interval, ok := binanceIntervals[period]
+	if !ok {
+		return quote.Quote{}, fmt.Errorf("binance: unsupported period %s", period)
+	}
+	pair, err := binanceSymbol(symbol)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+
+	q := quote.NewQuote(symbol, 0)
+	for from := start; from.Before(end); {
+		query := url.Values{}
+		query.Set("symbol", pair)
+		query.Set("interval", interval)
+		query.Set("startTime", strconv.FormatInt(from.UnixMilli(), 10))
+		query.Set("endTime", strconv.FormatInt(end.UnixMilli()-1, 10))
+		query.Set("limit", strconv.Itoa(binanceMaxBars))
+
+		// [openTime, open, high, low, close, volume, closeTime, ...], цены строками
+		var rows [][]any
+		if err := f.http.getJSON(ctx, "/api/v3/klines", query, &rows); err != nil {
+			return quote.Quote{}, fmt.Errorf("binance: %w", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		var last time.Time
+		for _, row := range rows {
+			if len(row) < 6 {
+				return quote.Quote{}, fmt.Errorf("binance: unexpected kline %v", row)
+			}
+			var vals [6]float64
+			for k := range vals {
+				if vals[k], err = parseFloatField(row[k]); err != nil {
+					return quote.Quote{}, fmt.Errorf("binance: %w", err)
+				}
+			}
+			last = time.UnixMilli(int64(vals[0])).UTC()
+			q.Date = append(q.Date, last)
+			q.Open = append(q.Open, vals[1])
+			q.High = append(q.High, vals[2])
+			q.Low = append(q.Low, vals[3])
+			q.Close = append(q.Close, vals[4])
+			q.Volume = append(q.Volume, vals[5])
+		}
+		if len(rows) < binanceMaxBars {
+			break
+		}
+		from = last.Add(time.Millisecond)
+		if err := waitPage(ctx); err != nil {
+			return quote.Quote{}, err
+		}
+	}
+
+	return utils.SliceQuote(q, start, end), nil
+}
+
+// Periods возвращает периоды, для которых у Binance есть интервал klines.
+func (f *FeederBinance) Periods() ([]quote.Period, error) {
+	var periods []quote.Period
+	for _, p := range utils.Periods {
+		if _, ok := binanceIntervals[p]; ok {
+			periods = append(periods, p)
+		}
+	}
+	return periods, nil
+}
+
+// binanceSymbol приводит BTC-USD к BTCUSDT: спотовые пары Binance котируются к USDT.
+func binanceSymbol(symbol string) (string, error) {
+	base, quoteCcy, err := splitSymbol(symbol)
+	if err != nil {
+		return "", err
+	}
+	if quoteCcy == "USD" {
+		quoteCcy = "USDT"
+	}
+	return base + quoteCcy, nil
+}
@@ -0,0 +1,128 @@
+package feeder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// binanceServer отдаёт klines с шагом step в пределах startTime/endTime и запоминает запросы.
+func binanceServer(t *testing.T, step time.Duration) (*httptest.Server, *[]url.Values) {
+	var requests []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/klines" {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		requests = append(requests, query)
+		if query.Get("symbol") != "BTCUSDT" {
+			http.Error(w, `{"code":-1121,"msg":"Invalid symbol."}`, http.StatusBadRequest)
+			return
+		}
+		from, _ := strconv.ParseInt(query.Get("startTime"), 10, 64)
+		to, _ := strconv.ParseInt(query.Get("endTime"), 10, 64)
+		limit, _ := strconv.Atoi(query.Get("limit"))
+
+		var rows [][]any
+		first := time.UnixMilli(from).UTC().Truncate(step)
+		if first.UnixMilli() < from {
+			first = first.Add(step)
+		}
+		for d := first; d.UnixMilli() <= to && len(rows) < limit; d = d.Add(step) {
+			price := fmt.Sprint(100 + d.Sub(first).Hours())
+			rows = append(rows, []any{d.UnixMilli(), price, price, price, price, "1.5", d.Add(step).UnixMilli() - 1})
+		}
+		writeJSON(t, w, rows)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestBinancePagination(t *testing.T) {
+	srv, requests := binanceServer(t, time.Minute)
+	f := NewFeederBinance(srv.URL)
+
+	// 2500 минутных баров - три страницы по 1000
+	q, err := f.GetQuote(context.Background(), "BTC-USD", "2024-01-01 00:00", "2024-01-02 17:40", quote.Min1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Date) != 2500 {
+		t.Fatalf("got %d bars, want 2500", len(q.Date))
+	}
+	if len(*requests) != 3 {
+		t.Errorf("got %d requests, want 3", len(*requests))
+	}
+	for i := 1; i < len(q.Date); i++ {
+		if q.Date[i].Sub(q.Date[i-1]) != time.Minute {
+			t.Fatalf("bar %d at %v follows %v", i, q.Date[i], q.Date[i-1])
+		}
+	}
+	if q.Volume[0] != 1.5 {
+		t.Errorf("volume = %v, want 1.5", q.Volume[0])
+	}
+}
+
+func TestBinanceIntervals(t *testing.T) {
+	tests := []struct {
+		period   quote.Period
+		interval string
+	}{
+		{quote.Min1, "1m"},
+		{quote.Min15, "15m"},
+		{quote.Min60, "1h"},
+		{quote.Hour4, "4h"},
+		{quote.Daily, "1d"},
+		{quote.Weekly, "1w"},
+		{quote.Monthly, "1M"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			srv, requests := binanceServer(t, time.Hour)
+			if _, err := NewFeederBinance(srv.URL).GetQuote(context.Background(), "BTCUSDT", "2024-01-01", "2024-01-01", tt.period); err != nil {
+				t.Fatal(err)
+			}
+			if got := (*requests)[0].Get("interval"); got != tt.interval {
+				t.Errorf("interval = %q, want %q", got, tt.interval)
+			}
+		})
+	}
+}
+
+func TestBinanceSymbol(t *testing.T) {
+	tests := []struct {
+		symbol, want string
+	}{
+		{"BTC-USD", "BTCUSDT"},
+		{"BTCUSDT", "BTCUSDT"},
+		{"btc/usdt", "BTCUSDT"},
+		{"ETH-EUR", "ETHEUR"},
+		{"ETHBTC", "ETHBTC"},
+	}
+	for _, tt := range tests {
+		if got, err := binanceSymbol(tt.symbol); err != nil || got != tt.want {
+			t.Errorf("binanceSymbol(%q) = %q, %v, want %q", tt.symbol, got, err, tt.want)
+		}
+	}
+}
+
+func TestBinanceErrorStatus(t *testing.T) {
+	srv, _ := binanceServer(t, time.Hour)
+	_, err := NewFeederBinance(srv.URL).GetQuote(context.Background(), "ETH-USD", "2024-01-01", "2024-01-02", quote.Min60)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("err = %v, want status 400", err)
+	}
+	if IsTransient(err) {
+		t.Error("400 must not be retried")
+	}
+}
@@ -0,0 +1,283 @@
+package feeder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/internal/resample"
+	"main/internal/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+const cacheTimeFormat = "2006-01-02 15:04"
+
+// FeederCache сохраняет котировки вложенного Feeder на диск в <dir>/<symbol>/<period>.json
+// и при повторных запросах догружает только недостающие диапазоны.
+type FeederCache struct {
+	inner Feeder
+	dir   string
+	now   func() time.Time
+
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+type CacheStats struct {
+	Requests   int   `json:"requests"`
+	Hits       int   `json:"hits"`       // запросы, полностью обслуженные из кэша
+	Fetches    int   `json:"fetches"`    // обращения к вложенному Feeder
+	BarsCached int   `json:"barsCached"` // баров на диске
+	Files      int   `json:"files"`
+	Bytes      int64 `json:"bytes"`
+}
+
+type cacheRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type cacheEntry struct {
+	Quote  quote.Quote  `json:"quote"`
+	Ranges []cacheRange `json:"ranges"`
+}
+
+func NewFeederCache(inner Feeder, dir string) *FeederCache {
+	return &FeederCache{
+		inner: inner,
+		dir:   dir,
+		now:   time.Now,
+	}
+}
+
+func (f *FeederCache) GetQuote(ctx context.Context, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
+	if err := validateSymbol(symbol); err != nil {
+		return quote.Quote{}, err
+	}
+	start, end, err := utils.ParseDateRange(startDate, endDate, time.UTC)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+
+	f.mu.Lock()
+	f.stats.Requests++
+	// без начальной даты невозможно понять, что уже загружено
+	if start.IsZero() {
+		f.stats.Fetches++
+		f.mu.Unlock()
+		return f.inner.GetQuote(ctx, symbol, startDate, endDate, period)
+	}
+	now := f.now().UTC()
+	if end.IsZero() || end.After(now) {
+		end = now
+	}
+	entry, err := f.load(symbol, period)
+	missing := missingRanges(entry.Ranges, cacheRange{Start: start, End: end})
+	if err == nil && len(missing) == 0 {
+		f.stats.Hits++
+	}
+	f.stats.Fetches += len(missing)
+	f.mu.Unlock()
+	if err != nil {
+		return quote.Quote{}, err
+	}
+
+	// сетевые запросы идут без блокировки, чтобы не задерживать другие символы
+	fetched := make([]quote.Quote, len(missing))
+	for i, m := range missing {
+		q, err := f.inner.GetQuote(ctx, symbol, m.Start.Format(cacheTimeFormat), m.End.Format(cacheTimeFormat), period)
+		if err != nil && !errors.Is(err, ErrNoData) {
+			return quote.Quote{}, err
+		}
+		// пустой диапазон тоже считается загруженным
+		fetched[i] = utils.SliceQuote(q, m.Start, m.End)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(missing) > 0 {
+		// файл мог измениться, пока шли запросы
+		if entry, err = f.load(symbol, period); err != nil {
+			return quote.Quote{}, err
+		}
+		// последний незакрытый бар не считается загруженным, чтобы обновить его позже
+		complete := resample.BarStart(now, period)
+		for i, m := range missing {
+			entry.Quote = mergeQuotes(entry.Quote, fetched[i])
+			if m.End.After(complete) {
+				m.End = complete
+			}
+			if m.Start.Before(m.End) {
+				entry.Ranges = addRange(entry.Ranges, m)
+			}
+		}
+		entry.Quote.Symbol = symbol
+		if err := f.save(symbol, period, entry); err != nil {
+			return quote.Quote{}, err
+		}
+	}
+
+	q := utils.SliceQuote(entry.Quote, start, end)
+	if len(q.Date) == 0 {
+		return quote.Quote{}, fmt.Errorf("%w for %s between %s and %s", ErrNoData, symbol, startDate, endDate)
+	}
+	return q, nil
+}
+
+// Stats возвращает счётчики запросов и размер кэша на диске.
+func (f *FeederCache) Stats() (CacheStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stats := f.stats
+	err := filepath.Walk(f.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		stats.Files++
+		stats.Bytes += info.Size()
+
+		var entry cacheEntry
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &entry); err == nil {
+			stats.BarsCached += len(entry.Quote.Date)
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+	}
+	return stats, err
+}
+
+// Purge удаляет кэш символа, конкретного периода символа или весь кэш при пустом symbol.
+func (f *FeederCache) Purge(symbol string, period quote.Period) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := f.dir
+	if symbol != "" {
+		if err := validateSymbol(symbol); err != nil {
+			return err
+		}
+		path = filepath.Join(f.dir, symbol)
+		if period != "" {
+			path = f.path(symbol, period)
+		}
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to purge cache: %w", err)
+	}
+	return nil
+}
+
+func (f *FeederCache) path(symbol string, period quote.Period) string {
+	return filepath.Join(f.dir, symbol, string(period)+".json")
+}
+
+func (f *FeederCache) load(symbol string, period quote.Period) (cacheEntry, error) {
+	var entry cacheEntry
+	data, err := os.ReadFile(f.path(symbol, period))
+	if errors.Is(err, os.ErrNotExist) {
+		return cacheEntry{Quote: quote.NewQuote(symbol, 0)}, nil
+	}
+	if err != nil {
+		return entry, fmt.Errorf("failed to read cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, fmt.Errorf("failed to unmarshal cache %s: %w", f.path(symbol, period), err)
+	}
+	if err := utils.CheckLengths(entry.Quote); err != nil {
+		return entry, fmt.Errorf("malformed cache %s: %w", f.path(symbol, period), err)
+	}
+	return entry, nil
+}
+
+func (f *FeederCache) save(symbol string, period quote.Period, entry cacheEntry) error {
+	path := f.path(symbol, period)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %w", err)
+	}
+	// пишем через временный файл, чтобы не оставить повреждённый кэш
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
+
+// missingRanges возвращает части want, не покрытые отсортированными непересекающимися ranges.
+func missingRanges(ranges []cacheRange, want cacheRange) []cacheRange {
+	var missing []cacheRange
+	cursor := want.Start
+	for _, r := range ranges {
+		if !r.End.After(cursor) {
+			continue
+		}
+		if !r.Start.Before(want.End) {
+			break
+		}
+		if r.Start.After(cursor) {
+			missing = append(missing, cacheRange{Start: cursor, End: r.Start})
+		}
+		cursor = r.End
+	}
+	if cursor.Before(want.End) {
+		missing = append(missing, cacheRange{Start: cursor, End: want.End})
+	}
+	return missing
+}
+
+// addRange добавляет диапазон и склеивает пересекающиеся и соседние.
+func addRange(ranges []cacheRange, r cacheRange) []cacheRange {
+	ranges = append(ranges, r)
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start.Before(ranges[j].Start) })
+
+	merged := ranges[:1]
+	for _, cur := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if !cur.Start.After(last.End) {
+			if cur.End.After(last.End) {
+				last.End = cur.End
+			}
+			continue
+		}
+		merged = append(merged, cur)
+	}
+	return merged
+}
+
+// mergeQuotes объединяет бары по дате, при совпадении побеждает бар из b.
+func mergeQuotes(a, b quote.Quote) quote.Quote {
+	bars := make(map[int64]int, len(a.Date)+len(b.Date))
+	out := quote.NewQuote(a.Symbol, 0)
+	out.Precision = a.Precision
+	for _, src := range []quote.Quote{a, b} {
+		for i, d := range src.Date {
+			if j, ok := bars[d.UnixNano()]; ok {
+				out.Open[j], out.High[j], out.Low[j] = src.Open[i], src.High[i], src.Low[i]
+				out.Close[j], out.Volume[j] = src.Close[i], src.Volume[i]
+				continue
+			}
+			bars[d.UnixNano()] = len(out.Date)
+			utils.AppendBar(&out, src, i)
+		}
+	}
+	return utils.SortQuote(out)
+}
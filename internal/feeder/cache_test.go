@@ -0,0 +1,240 @@
+package feeder
+
+import (
+	"context"
+	"errors"
+	"main/internal/utils"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// fakeFeeder отдаёт часовые бары за любой диапазон и запоминает запросы.
+type fakeFeeder struct {
+	calls []cacheRange
+	// empty - диапазоны, за которые источник отвечает ErrNoData
+	empty []cacheRange
+}
+
+func (f *fakeFeeder) GetQuote(_ context.Context, symbol, startDate, endDate string, _ quote.Period) (quote.Quote, error) {
+	start, end, err := utils.ParseDateRange(startDate, endDate, time.UTC)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+	f.calls = append(f.calls, cacheRange{Start: start, End: end})
+	q := quote.NewQuote(symbol, 0)
+	for t := start; t.Before(end); t = t.Add(time.Hour) {
+		if inRanges(f.empty, t) {
+			continue
+		}
+		v := float64(t.Unix() / 3600)
+		q.Date = append(q.Date, t)
+		q.Open = append(q.Open, v)
+		q.High = append(q.High, v+1)
+		q.Low = append(q.Low, v-1)
+		q.Close = append(q.Close, v)
+		q.Volume = append(q.Volume, 1)
+	}
+	if len(q.Date) == 0 {
+		return quote.Quote{}, ErrNoData
+	}
+	return q, nil
+}
+
+func inRanges(ranges []cacheRange, t time.Time) bool {
+	for _, r := range ranges {
+		if !t.Before(r.Start) && t.Before(r.End) {
+			return true
+		}
+	}
+	return false
+}
+
+func at(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func rng(start, end string) cacheRange {
+	return cacheRange{Start: at(start), End: at(end)}
+}
+
+func newTestCache(t *testing.T, inner Feeder, now string) *FeederCache {
+	f := NewFeederCache(inner, t.TempDir())
+	f.now = func() time.Time { return at(now) }
+	return f
+}
+
+func TestMissingRanges(t *testing.T) {
+	cached := []cacheRange{rng("2024-01-02 00:00", "2024-01-03 00:00"), rng("2024-01-05 00:00", "2024-01-06 00:00")}
+	tests := []struct {
+		name string
+		want cacheRange
+		miss []cacheRange
+	}{
+		{"inside", rng("2024-01-02 03:00", "2024-01-02 09:00"), nil},
+		{"before", rng("2024-01-01 00:00", "2024-01-02 00:00"), []cacheRange{rng("2024-01-01 00:00", "2024-01-02 00:00")}},
+		{"gap", rng("2024-01-02 12:00", "2024-01-05 12:00"), []cacheRange{rng("2024-01-03 00:00", "2024-01-05 00:00")}},
+		{"around", rng("2024-01-01 00:00", "2024-01-07 00:00"), []cacheRange{
+			rng("2024-01-01 00:00", "2024-01-02 00:00"),
+			rng("2024-01-03 00:00", "2024-01-05 00:00"),
+			rng("2024-01-06 00:00", "2024-01-07 00:00"),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingRanges(cached, tt.want); !reflect.DeepEqual(got, tt.miss) {
+				t.Errorf("missingRanges = %v, want %v", got, tt.miss)
+			}
+		})
+	}
+}
+
+func TestAddRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges []cacheRange
+		add    cacheRange
+		want   []cacheRange
+	}{
+		{"empty", nil, rng("2024-01-01 00:00", "2024-01-02 00:00"), []cacheRange{rng("2024-01-01 00:00", "2024-01-02 00:00")}},
+		{"adjacent", []cacheRange{rng("2024-01-01 00:00", "2024-01-02 00:00")}, rng("2024-01-02 00:00", "2024-01-03 00:00"),
+			[]cacheRange{rng("2024-01-01 00:00", "2024-01-03 00:00")}},
+		{"disjoint", []cacheRange{rng("2024-01-03 00:00", "2024-01-04 00:00")}, rng("2024-01-01 00:00", "2024-01-02 00:00"),
+			[]cacheRange{rng("2024-01-01 00:00", "2024-01-02 00:00"), rng("2024-01-03 00:00", "2024-01-04 00:00")}},
+		{"bridge", []cacheRange{rng("2024-01-01 00:00", "2024-01-02 00:00"), rng("2024-01-03 00:00", "2024-01-04 00:00")},
+			rng("2024-01-01 12:00", "2024-01-03 12:00"), []cacheRange{rng("2024-01-01 00:00", "2024-01-04 00:00")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addRange(tt.ranges, tt.add); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("addRange = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeQuotes(t *testing.T) {
+	a := quote.NewQuote("X", 0)
+	b := quote.NewQuote("X", 0)
+	for i, d := range []string{"2024-01-01 02:00", "2024-01-01 00:00"} {
+		utils.AppendBar(&a, quote.Quote{Date: []time.Time{at(d)}, Open: []float64{1}, High: []float64{1},
+			Low: []float64{1}, Close: []float64{float64(i)}, Volume: []float64{1}}, 0)
+	}
+	utils.AppendBar(&b, quote.Quote{Date: []time.Time{at("2024-01-01 02:00")}, Open: []float64{2}, High: []float64{2},
+		Low: []float64{2}, Close: []float64{9}, Volume: []float64{2}}, 0)
+	utils.AppendBar(&b, quote.Quote{Date: []time.Time{at("2024-01-01 01:00")}, Open: []float64{2}, High: []float64{2},
+		Low: []float64{2}, Close: []float64{5}, Volume: []float64{2}}, 0)
+
+	got := mergeQuotes(a, b)
+	wantDates := []time.Time{at("2024-01-01 00:00"), at("2024-01-01 01:00"), at("2024-01-01 02:00")}
+	if !reflect.DeepEqual(got.Date, wantDates) {
+		t.Fatalf("dates = %v, want %v", got.Date, wantDates)
+	}
+	if want := []float64{1, 5, 9}; !reflect.DeepEqual(got.Close, want) {
+		t.Errorf("close = %v, want %v", got.Close, want)
+	}
+}
+
+func TestCacheFetchesOnlyGap(t *testing.T) {
+	inner := &fakeFeeder{}
+	f := newTestCache(t, inner, "2024-02-01 00:00")
+	ctx := context.Background()
+
+	q, err := f.GetQuote(ctx, "BTC-USD", "2024-01-01", "2024-01-01", quote.Min60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Date) != 24 {
+		t.Fatalf("got %d bars, want 24", len(q.Date))
+	}
+	q, err = f.GetQuote(ctx, "BTC-USD", "2024-01-01", "2024-01-02", quote.Min60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Date) != 48 {
+		t.Fatalf("got %d bars, want 48", len(q.Date))
+	}
+	want := []cacheRange{rng("2024-01-01 00:00", "2024-01-02 00:00"), rng("2024-01-02 00:00", "2024-01-03 00:00")}
+	if !reflect.DeepEqual(inner.calls, want) {
+		t.Errorf("inner calls = %v, want %v", inner.calls, want)
+	}
+
+	if _, err := f.GetQuote(ctx, "BTC-USD", "2024-01-01 06:00", "2024-01-02 06:00", quote.Min60); err != nil {
+		t.Fatal(err)
+	}
+	if len(inner.calls) != 2 {
+		t.Errorf("cached range was fetched again: %v", inner.calls[2:])
+	}
+	stats, err := f.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Requests != 3 || stats.Hits != 1 || stats.Fetches != 2 || stats.BarsCached != 48 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestCacheOpenBarIsRefetched(t *testing.T) {
+	inner := &fakeFeeder{}
+	f := newTestCache(t, inner, "2024-01-01 10:30")
+	ctx := context.Background()
+
+	if _, err := f.GetQuote(ctx, "BTC-USD", "2024-01-01", "", quote.Min60); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := f.load("BTC-USD", quote.Min60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// бар 10:00 ещё открыт, загруженным считается диапазон до его начала
+	if want := []cacheRange{rng("2024-01-01 00:00", "2024-01-01 10:00")}; !reflect.DeepEqual(entry.Ranges, want) {
+		t.Errorf("ranges = %v, want %v", entry.Ranges, want)
+	}
+
+	f.now = func() time.Time { return at("2024-01-01 11:15") }
+	q, err := f.GetQuote(ctx, "BTC-USD", "2024-01-01", "", quote.Min60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := inner.calls[len(inner.calls)-1]; !last.Start.Equal(at("2024-01-01 10:00")) {
+		t.Errorf("second fetch starts at %v, want the open bar 10:00", last.Start)
+	}
+	if len(q.Date) != 12 {
+		t.Errorf("got %d bars, want 12", len(q.Date))
+	}
+}
+
+func TestCacheEmptySubrange(t *testing.T) {
+	inner := &fakeFeeder{empty: []cacheRange{rng("2024-01-02 00:00", "2024-01-03 00:00")}}
+	f := newTestCache(t, inner, "2024-02-01 00:00")
+	ctx := context.Background()
+
+	if _, err := f.GetQuote(ctx, "BTC-USD", "2024-01-01", "2024-01-01", quote.Min60); err != nil {
+		t.Fatal(err)
+	}
+	q, err := f.GetQuote(ctx, "BTC-USD", "2024-01-01", "2024-01-02", quote.Min60)
+	if err != nil {
+		t.Fatalf("empty sub-range failed the request: %v", err)
+	}
+	if len(q.Date) != 24 {
+		t.Errorf("got %d bars, want 24 cached bars", len(q.Date))
+	}
+	// пустой диапазон запомнен и больше не запрашивается
+	if _, err := f.GetQuote(ctx, "BTC-USD", "2024-01-01", "2024-01-02", quote.Min60); err != nil {
+		t.Fatal(err)
+	}
+	if len(inner.calls) != 2 {
+		t.Errorf("inner calls = %v, want 2", inner.calls)
+	}
+
+	_, err = f.GetQuote(ctx, "BTC-USD", "2024-01-02", "2024-01-02", quote.Min60)
+	if !errors.Is(err, ErrNoData) {
+		t.Errorf("err = %v, want ErrNoData", err)
+	}
+}
@@ -0,0 +1,130 @@
+package feeder
+
+import (
+	"context"
+	"fmt"
+	"main/internal/resample"
+	"main/internal/utils"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+const (
+	CoinbaseBaseURL = "https://api.exchange.coinbase.com"
+	coinbaseMaxBars = 300
+)
+
+// coinbaseGranularities - периоды, которые Coinbase отдаёт напрямую, от крупного к мелкому.
+var coinbaseGranularities = []time.Duration{
+	24 * time.Hour, 6 * time.Hour, time.Hour, 15 * time.Minute, 5 * time.Minute, time.Minute,
+}
+
+type FeederApiCoinbase struct {
+	http *httpClient
+}
+
+// NewFeederApiCoinbase создаёт фидер Coinbase, пустой baseURL - публичный API.
+func NewFeederApiCoinbase(baseURL string) *FeederApiCoinbase {
+	if baseURL == "" {
+		baseURL = CoinbaseBaseURL
+	}
+	return &FeederApiCoinbase{http: newHTTPClient(baseURL)}
+}
+
+// GetQuote загружает свечи постранично. Периоды, которых нет в API Coinbase,
+// собираются из ближайшего более мелкого поддерживаемого периода.
+func (f *FeederApiCoinbase) GetQuote(ctx context.Context, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
+	start, end, err := utils.ParseDateRange(startDate, endDate, time.UTC)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+	if start.IsZero() {
+		return quote.Quote{}, fmt.Errorf("start date is required")
+	}
+	if end.IsZero() || end.After(time.Now()) {
+		end = time.Now()
+	}
+	product, err := coinbaseProduct(symbol)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+
+	step := nativeGranularity(coinbaseGranularities, period)
+	q := quote.NewQuote(symbol, 0)
+
+	for pageStart := start; pageStart.Before(end); pageStart = pageStart.Add(coinbaseMaxBars * step) {
+		pageEnd := pageStart.Add((coinbaseMaxBars - 1) * step)
+		if pageEnd.After(end) {
+			pageEnd = end
+		}
+
+		page, err := f.fetchPage(ctx, symbol, product, pageStart, pageEnd, step)
+		if err != nil {
+			return quote.Quote{}, err
+		}
+		for i := range page.Date {
+			utils.AppendBar(&q, page, i)
+		}
+		if err := waitPage(ctx); err != nil {
+			return quote.Quote{}, err
+		}
+	}
+
+	q = utils.SliceQuote(utils.SortQuote(q), start, end)
+	if step == utils.PeriodDuration(period) {
+		return q, nil
+	}
+	return resample.Resample(q, period, resample.Options{Location: time.UTC, Base: step})
+}
+
+// Periods возвращает периоды, которые собираются из гранулярностей Coinbase.
+func (f *FeederApiCoinbase) Periods() ([]quote.Period, error) {
+	return buildablePeriods(nil, coinbaseGranularities...), nil
+}
+
+func (f *FeederApiCoinbase) fetchPage(ctx context.Context, symbol, product string, start, end time.Time, step time.Duration) (quote.Quote, error) {
+	query := url.Values{}
+	query.Set("start", start.Format(time.RFC3339))
+	query.Set("end", end.Format(time.RFC3339))
+	query.Set("granularity", strconv.Itoa(int(step.Seconds())))
+
+	// [time, low, high, open, close, volume], новые свечи первыми
+	var bars [][6]float64
+	if err := f.http.getJSON(ctx, "/products/"+url.PathEscape(product)+"/candles", query, &bars); err != nil {
+		return quote.Quote{}, fmt.Errorf("coinbase: %w", err)
+	}
+
+	q := quote.NewQuote(symbol, len(bars))
+	for row, bar := range bars {
+		i := len(bars) - 1 - row
+		q.Date[i] = time.Unix(int64(bar[0]), 0).UTC()
+		q.Low[i] = bar[1]
+		q.High[i] = bar[2]
+		q.Open[i] = bar[3]
+		q.Close[i] = bar[4]
+		q.Volume[i] = bar[5]
+	}
+	return q, nil
+}
+
+// coinbaseProduct приводит символ к виду BTC-USD.
+func coinbaseProduct(symbol string) (string, error) {
+	base, quoteCcy, err := splitSymbol(symbol)
+	if err != nil {
+		return "", err
+	}
+	return base + "-" + quoteCcy, nil
+}
+
+// nativeGranularity выбирает крупнейший поддерживаемый биржей период, из которого собирается period.
+func nativeGranularity(supported []time.Duration, period quote.Period) time.Duration {
+	for _, g := range supported {
+		if resample.CanResample(g, period) {
+			return g
+		}
+	}
+	return supported[len(supported)-1]
+}
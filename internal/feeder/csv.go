@@ -0,0 +1,329 @@
+package feeder
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"main/internal/resample"
+	"main/internal/utils"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// CSVConfig описывает формат CSV-файлов с OHLCV-данными.
+type CSVConfig struct {
+	// Path - файл или каталог. Для каталога используется файл <Path>/<symbol>.csv
+	Path      string
+	Delimiter rune
+	HasHeader bool
+	// Columns - соответствие поля (date, open, high, low, close, volume) имени колонки
+	// из заголовка или её индексу, начиная с 0
+	Columns map[string]string
+	// TimeFormat - layout для time.Parse либо unix / unixms
+	TimeFormat string
+	Location   *time.Location
+}
+
+var csvFields = []string{"date", "open", "high", "low", "close", "volume"}
+
+func DefaultCSVConfig() CSVConfig {
+	return CSVConfig{
+		Path:      "./example",
+		Delimiter: ',',
+		HasHeader: true,
+		Columns: map[string]string{
+			"date":   "date",
+			"open":   "open",
+			"high":   "high",
+			"low":    "low",
+			"close":  "close",
+			"volume": "volume",
+		},
+		TimeFormat: time.RFC3339,
+		Location:   time.UTC,
+	}
+}
+
+// CSVConfigFromEnv читает настройки из переменных окружения FEEDER_CSV_*.
+func CSVConfigFromEnv(env Env) (CSVConfig, error) {
+	cfg := DefaultCSVConfig()
+
+	if v := env("FEEDER_CSV_PATH"); v != "" {
+		cfg.Path = v
+	}
+	if v := env("FEEDER_CSV_DELIMITER"); v != "" {
+		if v == `\t` {
+			v = "\t"
+		}
+		if len([]rune(v)) != 1 {
+			return cfg, fmt.Errorf("FEEDER_CSV_DELIMITER must be a single character, got %q", v)
+		}
+		cfg.Delimiter = []rune(v)[0]
+	}
+	if v := env("FEEDER_CSV_HEADER"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid FEEDER_CSV_HEADER: %w", err)
+		}
+		cfg.HasHeader = b
+	}
+	if v := env("FEEDER_CSV_COLUMNS"); v != "" {
+		// формат: date=timestamp,open=o,high=h,low=l,close=c,volume=v
+		for _, pair := range strings.Split(v, ",") {
+			field, column, ok := strings.Cut(pair, "=")
+			field = strings.ToLower(strings.TrimSpace(field))
+			if !ok || !isCSVField(field) {
+				return cfg, fmt.Errorf("invalid FEEDER_CSV_COLUMNS entry %q", pair)
+			}
+			cfg.Columns[field] = strings.TrimSpace(column)
+		}
+	}
+	if v := env("FEEDER_CSV_TIME_FORMAT"); v != "" {
+		cfg.TimeFormat = v
+	}
+	if v := env("FEEDER_CSV_TIMEZONE"); v != "" {
+		loc, err := time.LoadLocation(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid FEEDER_CSV_TIMEZONE: %w", err)
+		}
+		cfg.Location = loc
+	}
+	return cfg, nil
+}
+
+func isCSVField(field string) bool {
+	for _, f := range csvFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+type FeederCSVFile struct {
+	config CSVConfig
+}
+
+func NewFeederCSVFile(config CSVConfig) *FeederCSVFile {
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	if config.Delimiter == 0 {
+		config.Delimiter = ','
+	}
+	return &FeederCSVFile{config: config}
+}
+
+func (f *FeederCSVFile) GetQuote(ctx context.Context, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return quote.Quote{}, err
+	}
+	if err := validateSymbol(symbol); err != nil {
+		return quote.Quote{}, err
+	}
+	start, end, err := utils.ParseDateRange(startDate, endDate, f.config.Location)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+
+	q, err := f.readFile(f.filePath(symbol), symbol)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+
+	// шаг определяем по всему файлу: в срезе может оказаться один бар
+	base := resample.DetectPeriod(q)
+	q = utils.SliceQuote(q, start, end)
+	if len(q.Date) == 0 {
+		return quote.Quote{}, fmt.Errorf("%w for %s between %s and %s", ErrNoData, symbol, startDate, endDate)
+	}
+
+	return resample.Resample(q, period, resample.Options{Location: f.config.Location, Base: base})
+}
+
+// Symbols перечисляет файлы <symbol>.csv. Если Path - один файл, фидер принимает любой символ.
+func (f *FeederCSVFile) Symbols() ([]string, error) {
+	info, err := os.Stat(f.config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", f.config.Path, err)
+	}
+	if !info.IsDir() {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(f.config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", f.config.Path, err)
+	}
+	symbols := []string{}
+	for _, e := range entries {
+		if symbol, ok := strings.CutSuffix(e.Name(), ".csv"); ok && !e.IsDir() {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return dedupeSorted(symbols), nil
+}
+
+// Periods возвращает периоды, которые строятся из баров CSV-файлов.
+func (f *FeederCSVFile) Periods() ([]quote.Period, error) {
+	paths := []string{f.config.Path}
+	symbols, err := f.Symbols()
+	if err != nil {
+		return nil, err
+	}
+	if symbols != nil {
+		paths = paths[:0]
+		for _, symbol := range symbols {
+			paths = append(paths, f.filePath(symbol))
+		}
+	}
+	var bases []time.Duration
+	for _, path := range paths {
+		q, err := f.readFile(path, filepath.Base(path))
+		if err != nil {
+			return nil, err
+		}
+		if base := resample.DetectPeriod(q); base > 0 {
+			bases = append(bases, base)
+		}
+	}
+	return buildablePeriods(nil, bases...), nil
+}
+
+func (f *FeederCSVFile) filePath(symbol string) string {
+	info, err := os.Stat(f.config.Path)
+	if err == nil && info.IsDir() {
+		return filepath.Join(f.config.Path, symbol+".csv")
+	}
+	return f.config.Path
+}
+
+func (f *FeederCSVFile) readFile(path, symbol string) (quote.Quote, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return quote.Quote{}, fmt.Errorf("failed to open csv for %s: %w", symbol, err)
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.Comma = f.config.Delimiter
+	r.TrimLeadingSpace = true
+	r.ReuseRecord = true
+
+	var header []string
+	if f.config.HasHeader {
+		rec, err := r.Read()
+		if err != nil {
+			return quote.Quote{}, fmt.Errorf("failed to read csv header %s: %w", path, err)
+		}
+		header = append(header, rec...)
+	}
+
+	cols, err := f.resolveColumns(header)
+	if err != nil {
+		return quote.Quote{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	q := quote.NewQuote(symbol, 0)
+	line := 1
+	if f.config.HasHeader {
+		line++
+	}
+	for ; ; line++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return quote.Quote{}, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+
+		date, err := f.parseTime(field(rec, cols["date"]))
+		if err != nil {
+			return quote.Quote{}, fmt.Errorf("%s:%d: invalid date: %w", path, line, err)
+		}
+		var vals [5]float64
+		for k, name := range csvFields[1:] {
+			idx, ok := cols[name]
+			if !ok {
+				continue
+			}
+			vals[k], err = strconv.ParseFloat(field(rec, idx), 64)
+			if err != nil {
+				return quote.Quote{}, fmt.Errorf("%s:%d: invalid %s: %w", path, line, name, err)
+			}
+		}
+
+		q.Date = append(q.Date, date)
+		q.Open = append(q.Open, vals[0])
+		q.High = append(q.High, vals[1])
+		q.Low = append(q.Low, vals[2])
+		q.Close = append(q.Close, vals[3])
+		q.Volume = append(q.Volume, vals[4])
+	}
+
+	return utils.SortQuote(q), nil
+}
+
+// resolveColumns переводит настройки колонок в индексы. Колонка volume необязательна.
+func (f *FeederCSVFile) resolveColumns(header []string) (map[string]int, error) {
+	cols := make(map[string]int, len(csvFields))
+	for _, name := range csvFields {
+		column, ok := f.config.Columns[name]
+		if !ok || column == "" {
+			if name == "volume" {
+				continue
+			}
+			return nil, fmt.Errorf("no column configured for %s", name)
+		}
+		if idx, err := strconv.Atoi(column); err == nil {
+			cols[name] = idx
+			continue
+		}
+		found := false
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), column) {
+				cols[name] = i
+				found = true
+				break
+			}
+		}
+		if !found {
+			if name == "volume" {
+				continue
+			}
+			return nil, fmt.Errorf("column %q for %s not found in header", column, name)
+		}
+	}
+	return cols, nil
+}
+
+func (f *FeederCSVFile) parseTime(s string) (time.Time, error) {
+	switch strings.ToLower(f.config.TimeFormat) {
+	case "unix":
+		sec, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(int64(sec), 0).In(f.config.Location), nil
+	case "unixms":
+		ms, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.UnixMilli(ms).In(f.config.Location), nil
+	}
+	return time.ParseInLocation(f.config.TimeFormat, s, f.config.Location)
+}
+
+func field(rec []string, idx int) string {
+	if idx < 0 || idx >= len(rec) {
+		return ""
+	}
+	return strings.TrimSpace(rec[idx])
+}
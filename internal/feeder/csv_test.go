@@ -0,0 +1,180 @@
+package feeder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCSVColumns(t *testing.T) {
+	columns := func(pairs ...string) map[string]string {
+		m := map[string]string{}
+		for i := 0; i < len(pairs); i += 2 {
+			m[pairs[i]] = pairs[i+1]
+		}
+		return m
+	}
+	tests := []struct {
+		name    string
+		content string
+		config  CSVConfig
+		volume  float64
+		err     string
+	}{
+		{"default header", "date,open,high,low,close,volume\n2024-01-01T00:00:00Z,1,3,0.5,2,10\n2024-01-01T01:00:00Z,2,2,2,2,1\n",
+			DefaultCSVConfig(), 10, ""},
+		// колонки в другом порядке и с другими именами, регистр заголовка не важен
+		{"mapped header", "Close;TS;O;H;L;Vol\n2;1704067200;1;3;0.5;10\n2;1704070800;2;2;2;1\n",
+			CSVConfig{Delimiter: ';', HasHeader: true, TimeFormat: "unix",
+				Columns: columns("date", "ts", "open", "o", "high", "h", "low", "l", "close", "close", "volume", "vol")}, 10, ""},
+		{"indexes without header", "1704067200000,1,3,0.5,2\n1704070800000,2,2,2,2\n",
+			CSVConfig{TimeFormat: "unixms", Columns: columns("date", "0", "open", "1", "high", "2", "low", "3", "close", "4")}, 0, ""},
+		{"volume column missing", "date,open,high,low,close\n2024-01-01T00:00:00Z,1,3,0.5,2\n2024-01-01T01:00:00Z,2,2,2,2\n",
+			DefaultCSVConfig(), 0, ""},
+		{"required column missing", "date,open,high,low,price\n2024-01-01T00:00:00Z,1,3,0.5,2\n",
+			DefaultCSVConfig(), 0, `column "close" for close not found in header`},
+		{"column not configured", "date,open,high,low,close\n2024-01-01T00:00:00Z,1,3,0.5,2\n",
+			CSVConfig{HasHeader: true, TimeFormat: time.RFC3339, Columns: columns("date", "date", "open", "open")}, 0, "no column configured for high"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Path = writeFile(t, t.TempDir(), "BTC-USD.csv", tt.content)
+			q, err := NewFeederCSVFile(tt.config).GetQuote(context.Background(), "BTC-USD", "", "", quote.Min60)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			bar := []float64{q.Open[0], q.High[0], q.Low[0], q.Close[0], q.Volume[0]}
+			if len(q.Date) != 2 || !q.Date[0].Equal(at("2024-01-01 00:00")) || !slices.Equal(bar, []float64{1, 3, 0.5, 2, tt.volume}) {
+				t.Errorf("got %v %v", q.Date, bar)
+			}
+		})
+	}
+}
+
+func TestCSVBadRows(t *testing.T) {
+	const header = "date,open,high,low,close,volume\n"
+	const good = "2024-01-01T00:00:00Z,1,3,0.5,2,10\n"
+	tests := []struct {
+		name string
+		rows string
+		err  string
+	}{
+		{"bad date", good + "01/01/2024,1,3,0.5,2,10\n", ":3: invalid date"},
+		{"bad price", "2024-01-01T00:00:00Z,1,3,0.5,n/a,10\n", ":2: invalid close"},
+		{"short row", good + "2024-01-01T01:00:00Z,1,3\n", ":3: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultCSVConfig()
+			cfg.Path = writeFile(t, t.TempDir(), "BTC-USD.csv", header+tt.rows)
+			_, err := NewFeederCSVFile(cfg).GetQuote(context.Background(), "BTC-USD", "", "", quote.Min60)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCSVDateRange(t *testing.T) {
+	// три дня часовых баров в обратном порядке: файл сортируется при чтении
+	var b strings.Builder
+	b.WriteString("date,open,high,low,close,volume\n")
+	for i := 71; i >= 0; i-- {
+		d := at("2024-01-01 00:00").Add(time.Duration(i) * time.Hour)
+		fmt.Fprintf(&b, "%s,%d,%d,%d,%d,1\n", d.Format(time.RFC3339), i, i+1, i, i)
+	}
+	cfg := DefaultCSVConfig()
+	cfg.Path = t.TempDir()
+	writeFile(t, cfg.Path, "BTC-USD.csv", b.String())
+	f := NewFeederCSVFile(cfg)
+
+	tests := []struct {
+		name       string
+		start, end string
+		period     quote.Period
+		first      string
+		bars       int
+		err        error
+	}{
+		// дата окончания без времени включает весь день
+		{"one day", "2024-01-02", "2024-01-02", quote.Min60, "2024-01-02 00:00", 24, nil},
+		{"open start", "", "2024-01-01 05:00", quote.Min60, "2024-01-01 00:00", 5, nil},
+		{"resampled", "2024-01-02", "", quote.Daily, "2024-01-02 00:00", 2, nil},
+		{"outside the file", "2024-02-01", "2024-02-02", quote.Min60, "", 0, ErrNoData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := f.GetQuote(context.Background(), "BTC-USD", tt.start, tt.end, tt.period)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(q.Date) != tt.bars || !q.Date[0].Equal(at(tt.first)) {
+				t.Fatalf("got %d bars from %s, want %d from %s", len(q.Date), q.Date[0], tt.bars, tt.first)
+			}
+			if !slices.IsSortedFunc(q.Date, time.Time.Compare) {
+				t.Error("bars are not sorted")
+			}
+		})
+	}
+
+	if _, err := f.GetQuote(context.Background(), "BTC-USD", "2024-01-03", "2024-01-02", quote.Min60); err == nil {
+		t.Error("expected an error for start after end")
+	}
+	if _, err := f.GetQuote(context.Background(), "../BTC-USD", "", "", quote.Min60); err == nil {
+		t.Error("expected an error for a symbol outside the directory")
+	}
+}
+
+func TestCSVConfigFromEnv(t *testing.T) {
+	env := map[string]string{
+		"FEEDER_CSV_DELIMITER": `\t`,
+		"FEEDER_CSV_HEADER":    "false",
+		"FEEDER_CSV_COLUMNS":   "date=0, Close=4",
+	}
+	cfg, err := CSVConfigFromEnv(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Delimiter != '\t' || cfg.HasHeader || cfg.Columns["date"] != "0" || cfg.Columns["close"] != "4" || cfg.Columns["open"] != "open" {
+		t.Errorf("config = %+v", cfg)
+	}
+
+	for _, bad := range []map[string]string{
+		{"FEEDER_CSV_DELIMITER": ";;"},
+		{"FEEDER_CSV_COLUMNS": "price=4"},
+		{"FEEDER_CSV_TIMEZONE": "Mars/Olympus"},
+	} {
+		if _, err := CSVConfigFromEnv(func(key string) string { return bad[key] }); err == nil {
+			t.Errorf("%v: expected an error", bad)
+		}
+	}
+}
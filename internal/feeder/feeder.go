@@ -1,31 +1,16 @@
 package feeder
 
 import (
+	"context"
+	"errors"
+
 	"github.com/markcheno/go-quote"
 )
 
 type Feeder interface {
-	GetQuote(symbol, startDate, endDate string, period quote.Period) (quote.Quote, error)
-}
-
-type FeederApiCoinbase struct{}
-
-type FeederJSONFile struct{}
-
-func NewFeederApiCoinbase() *FeederApiCoinbase {
-	return &FeederApiCoinbase{}
+	GetQuote(ctx context.Context, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error)
 }
 
-func NewFeederJSONFile() *FeederJSONFile {
-	return &FeederJSONFile{}
-}
-
-func (f *FeederApiCoinbase) GetQuote(symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
-	q, err := quote.NewQuoteFromCoinbase(symbol, startDate, endDate, period)
-	return q, err
-}
-
-func (f *FeederJSONFile) GetQuote(symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
-	q, err := quote.NewQuoteFromJSONFile("./example/BTC-USD.json")
-	return q, err
-}
+// ErrNoData - в запрошенном диапазоне нет баров. Такой диапазон считается загруженным,
+// но пустым, в отличие от ошибок источника.
+var ErrNoData = errors.New("no data")
@@ -0,0 +1,93 @@
+package feeder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pageWait - пауза между страницами, чтобы не упираться в лимиты бирж.
+const pageWait = 200 * time.Millisecond
+
+// httpClient - общий клиент REST-фидеров. Базовый URL задаётся при создании,
+// поэтому фидер можно направить на httptest-сервер.
+type httpClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+func newHTTPClient(baseURL string) *httpClient {
+	return &httpClient{
+		client:  &http.Client{},
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// getJSON выполняет GET baseURL+path и декодирует ответ в dst.
+// Неуспешный статус возвращается как *StatusError.
+func (c *httpClient) getJSON(ctx context.Context, path string, query url.Values, dst any) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request %s failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read %s failed: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	if err := json.Unmarshal(body, dst); err != nil {
+		return fmt.Errorf("decode %s failed: %w", path, err)
+	}
+	return nil
+}
+
+// waitPage делает паузу между запросами страниц с учётом отмены.
+func waitPage(ctx context.Context) error {
+	return sleep(ctx, pageWait)
+}
+
+// splitSymbol разбирает BTC-USD, BTC/USD, BTC_USD или BTCUSDT на базовую и котируемую валюты.
+func splitSymbol(symbol string) (base, quoteCcy string, err error) {
+	s := strings.ToUpper(strings.TrimSpace(symbol))
+	for _, sep := range []string{"-", "/", "_"} {
+		if b, q, ok := strings.Cut(s, sep); ok && b != "" && q != "" {
+			return b, q, nil
+		}
+	}
+	for _, q := range []string{"USDT", "USDC", "BUSD", "FDUSD", "USD", "EUR", "GBP", "BTC", "ETH"} {
+		if b, ok := strings.CutSuffix(s, q); ok && b != "" {
+			return b, q, nil
+		}
+	}
+	return "", "", fmt.Errorf("cannot split symbol %q into base and quote currency", symbol)
+}
+
+func parseFloatField(v any) (float64, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case string:
+		return strconv.ParseFloat(x, 64)
+	}
+	return 0, fmt.Errorf("unexpected value %v", v)
+}
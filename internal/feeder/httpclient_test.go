@@ -0,0 +1,116 @@
+package feeder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPClientGetJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/items" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Accept"); got != "application/json" {
+			t.Errorf("Accept = %q", got)
+		}
+		if got := r.URL.Query().Get("symbol"); got != "BTC USD" {
+			t.Errorf("symbol = %q", got)
+		}
+		w.Write([]byte(`{"value": 42}`))
+	}))
+	defer srv.Close()
+
+	var dst struct {
+		Value int `json:"value"`
+	}
+	c := newHTTPClient(srv.URL + "/")
+	if err := c.getJSON(context.Background(), "/v1/items", map[string][]string{"symbol": {"BTC USD"}}, &dst); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Value != 42 {
+		t.Errorf("value = %d, want 42", dst.Value)
+	}
+}
+
+func TestHTTPClientErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.Error(w, "no such thing", http.StatusNotFound)
+		case "/busy":
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		case "/broken":
+			w.Write([]byte(`{"value":`))
+		}
+	}))
+	defer srv.Close()
+	c := newHTTPClient(srv.URL)
+	var dst map[string]any
+
+	tests := []struct {
+		path      string
+		status    int
+		transient bool
+	}{
+		{"/missing", http.StatusNotFound, false},
+		{"/busy", http.StatusTooManyRequests, true},
+		{"/broken", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := c.getJSON(context.Background(), tt.path, nil, &dst)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			var statusErr *StatusError
+			if got := errors.As(err, &statusErr); got != (tt.status != 0) {
+				t.Fatalf("StatusError = %v for %v", got, err)
+			}
+			if statusErr != nil && statusErr.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", statusErr.StatusCode, tt.status)
+			}
+			if IsTransient(err) != tt.transient {
+				t.Errorf("IsTransient = %v, want %v", !tt.transient, tt.transient)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.getJSON(ctx, "/missing", nil, &dst); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestSplitSymbol(t *testing.T) {
+	tests := []struct {
+		symbol, base, quote string
+	}{
+		{"BTC-USD", "BTC", "USD"},
+		{"eth/eur", "ETH", "EUR"},
+		{"SOL_USDC", "SOL", "USDC"},
+		{"BTCUSDT", "BTC", "USDT"},
+		{"ETHBTC", "ETH", "BTC"},
+	}
+	for _, tt := range tests {
+		base, quoteCcy, err := splitSymbol(tt.symbol)
+		if err != nil || base != tt.base || quoteCcy != tt.quote {
+			t.Errorf("splitSymbol(%q) = %q, %q, %v", tt.symbol, base, quoteCcy, err)
+		}
+	}
+	if _, _, err := splitSymbol("BTC"); err == nil {
+		t.Error("splitSymbol(BTC) must fail")
+	}
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Error(err)
+	}
+}
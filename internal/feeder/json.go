@@ -0,0 +1,164 @@
+package feeder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/resample"
+	"main/internal/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// FeederJSONFile читает котировки из каталога с JSON-файлами в формате go-quote.
+// Поддерживаются два варианта раскладки:
+//
+//	<dataDir>/<symbol>/<period>.json - отдельный файл на каждый период
+//	<dataDir>/<symbol>.json          - один файл, из которого строятся более крупные периоды
+type FeederJSONFile struct {
+	dataDir string
+}
+
+func NewFeederJSONFile(dataDir string) *FeederJSONFile {
+	return &FeederJSONFile{dataDir: dataDir}
+}
+
+// JSONDataDirFromEnv возвращает каталог из FEEDER_JSON_DIR или ./example по умолчанию.
+func JSONDataDirFromEnv(env Env) string {
+	if dir := env("FEEDER_JSON_DIR"); dir != "" {
+		return dir
+	}
+	return "./example"
+}
+
+func (f *FeederJSONFile) GetQuote(ctx context.Context, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return quote.Quote{}, err
+	}
+	if err := validateSymbol(symbol); err != nil {
+		return quote.Quote{}, err
+	}
+	start, end, err := utils.ParseDateRange(startDate, endDate, nil)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+
+	q, exact, err := f.load(symbol, period)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+	if err := utils.CheckLengths(q); err != nil {
+		return quote.Quote{}, fmt.Errorf("json data for %s is malformed: %w", symbol, err)
+	}
+	if len(q.Date) == 0 {
+		return quote.Quote{}, fmt.Errorf("json data for %s is empty", symbol)
+	}
+	q = utils.SortQuote(q)
+
+	first, last := q.Date[0], q.Date[len(q.Date)-1]
+	// шаг определяем по всему файлу: в срезе может оказаться один бар
+	base := resample.DetectPeriod(q)
+	q = utils.SliceQuote(q, start, end)
+	if len(q.Date) == 0 {
+		return quote.Quote{}, fmt.Errorf("%w for %s between %s and %s, available range is %s - %s",
+			ErrNoData, symbol, startDate, endDate, first.Format("2006-01-02 15:04"), last.Format("2006-01-02 15:04"))
+	}
+
+	if exact {
+		return q, nil
+	}
+	return resample.Resample(q, period, resample.Options{Base: base})
+}
+
+// Symbols перечисляет файлы <symbol>.json и каталоги <symbol>/ в dataDir.
+func (f *FeederJSONFile) Symbols() ([]string, error) {
+	entries, err := os.ReadDir(f.dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", f.dataDir, err)
+	}
+	symbols := []string{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() {
+			symbols = append(symbols, name)
+		} else if symbol, ok := strings.CutSuffix(name, ".json"); ok {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return dedupeSorted(symbols), nil
+}
+
+// Periods возвращает периоды файлов <symbol>/<period>.json и периоды, которые
+// строятся из баров файлов <symbol>.json, по всем символам каталога.
+func (f *FeederJSONFile) Periods() ([]quote.Period, error) {
+	symbols, err := f.Symbols()
+	if err != nil {
+		return nil, err
+	}
+	var exact []quote.Period
+	var bases []time.Duration
+	for _, symbol := range symbols {
+		for _, p := range utils.Periods {
+			if _, err := os.Stat(filepath.Join(f.dataDir, symbol, string(p)+".json")); err == nil {
+				exact = append(exact, p)
+			}
+		}
+		q, err := quote.NewQuoteFromJSONFile(filepath.Join(f.dataDir, symbol+".json"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s.json: %w", symbol, err)
+		}
+		if base := resample.DetectPeriod(q); base > 0 {
+			bases = append(bases, base)
+		}
+	}
+	return buildablePeriods(exact, bases...), nil
+}
+
+// load ищет файл периода, затем общий файл символа. exact сообщает, что файл уже в нужном периоде.
+func (f *FeederJSONFile) load(symbol string, period quote.Period) (q quote.Quote, exact bool, err error) {
+	periodPath := filepath.Join(f.dataDir, symbol, string(period)+".json")
+	q, err = quote.NewQuoteFromJSONFile(periodPath)
+	if err == nil {
+		return q, true, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return q, false, fmt.Errorf("failed to read %s: %w", periodPath, err)
+	}
+
+	symbolPath := filepath.Join(f.dataDir, symbol+".json")
+	q, err = quote.NewQuoteFromJSONFile(symbolPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, false, fmt.Errorf("symbol %s is not available in %s", symbol, f.dataDir)
+	}
+	if err != nil {
+		return q, false, fmt.Errorf("failed to read %s: %w", symbolPath, err)
+	}
+	return q, false, nil
+}
+
+// validateSymbol не допускает выход за пределы каталога с данными.
+func validateSymbol(symbol string) error {
+	if symbol == "" || symbol == "." || symbol == ".." || strings.ContainsAny(symbol, `/\`) {
+		return fmt.Errorf("invalid symbol %q", symbol)
+	}
+	return nil
+}
+
+func dedupeSorted(values []string) []string {
+	sort.Strings(values)
+	out := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
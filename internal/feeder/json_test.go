@@ -0,0 +1,128 @@
+package feeder
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// hourlyQuote строит n часовых баров с 2024-01-01, close бара i равен i.
+func hourlyQuote(symbol string, n int) quote.Quote {
+	q := quote.NewQuote(symbol, 0)
+	for i := range n {
+		f := float64(i)
+		q.Date = append(q.Date, at("2024-01-01 00:00").Add(time.Duration(i)*time.Hour))
+		q.Open = append(q.Open, f)
+		q.High = append(q.High, f+1)
+		q.Low = append(q.Low, f)
+		q.Close = append(q.Close, f)
+		q.Volume = append(q.Volume, 1)
+	}
+	return q
+}
+
+func writeQuote(t *testing.T, path string, q quote.Quote) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.WriteJSON(path, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJSONFile(t *testing.T) {
+	dir := t.TempDir()
+	writeQuote(t, filepath.Join(dir, "BTC-USD.json"), hourlyQuote("BTC-USD", 72))
+	// отдельный файл периода важнее общего файла символа
+	daily := hourlyQuote("BTC-USD", 3)
+	for i := range daily.Date {
+		daily.Date[i] = at("2024-01-01 00:00").AddDate(0, 0, i)
+		daily.Close[i] = 100
+	}
+	writeQuote(t, filepath.Join(dir, "BTC-USD", "d.json"), daily)
+	f := NewFeederJSONFile(dir)
+
+	tests := []struct {
+		name       string
+		start, end string
+		period     quote.Period
+		first      string
+		closes     []float64
+	}{
+		{"whole file", "", "", quote.Min60, "2024-01-01 00:00", nil},
+		{"date range", "2024-01-02 10:00", "2024-01-02 13:00", quote.Min60, "2024-01-02 10:00", []float64{34, 35, 36}},
+		// 4h строится из часовых баров: close - последнего бара периода
+		{"resampled", "2024-01-03", "2024-01-03", quote.Hour4, "2024-01-03 00:00", []float64{51, 55, 59, 63, 67, 71}},
+		{"period file", "2024-01-02", "", quote.Daily, "2024-01-02 00:00", []float64{100, 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := f.GetQuote(context.Background(), "BTC-USD", tt.start, tt.end, tt.period)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !q.Date[0].Equal(at(tt.first)) {
+				t.Errorf("first bar = %s, want %s", q.Date[0], tt.first)
+			}
+			if tt.closes == nil {
+				if len(q.Date) != 72 {
+					t.Errorf("got %d bars, want 72", len(q.Date))
+				}
+				return
+			}
+			if !slices.Equal(q.Close, tt.closes) {
+				t.Errorf("closes = %v, want %v", q.Close, tt.closes)
+			}
+		})
+	}
+}
+
+func TestJSONFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeQuote(t, filepath.Join(dir, "BTC-USD.json"), hourlyQuote("BTC-USD", 24))
+	malformed := hourlyQuote("ETH-USD", 24)
+	malformed.Volume = malformed.Volume[:10]
+	writeQuote(t, filepath.Join(dir, "ETH-USD.json"), malformed)
+	if err := os.WriteFile(filepath.Join(dir, "SOL-USD.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeQuote(t, filepath.Join(dir, "XRP-USD.json"), quote.NewQuote("XRP-USD", 0))
+	f := NewFeederJSONFile(dir)
+
+	tests := []struct {
+		name       string
+		symbol     string
+		start, end string
+		err        string
+	}{
+		{"outside the file", "BTC-USD", "2024-02-01", "2024-02-02", "available range is 2024-01-01 00:00 - 2024-01-01 23:00"},
+		{"start after end", "BTC-USD", "2024-01-02", "2024-01-01", "is not before end date"},
+		{"bad date", "BTC-USD", "01/01/2024", "", "invalid start date"},
+		{"unknown symbol", "DOGE-USD", "", "", "symbol DOGE-USD is not available"},
+		{"path outside the directory", "..", "", "", "invalid symbol"},
+		{"mismatched lengths", "ETH-USD", "", "", "malformed"},
+		{"not json", "SOL-USD", "", "", "failed to read"},
+		{"empty file", "XRP-USD", "", "", "is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.GetQuote(context.Background(), tt.symbol, tt.start, tt.end, quote.Min60)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+
+	_, err := f.GetQuote(context.Background(), "BTC-USD", "2024-02-01", "", quote.Min60)
+	if !errors.Is(err, ErrNoData) {
+		t.Errorf("err = %v, want ErrNoData", err)
+	}
+}
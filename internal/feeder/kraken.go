@@ -0,0 +1,191 @@
+package feeder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"main/internal/resample"
+	"main/internal/utils"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+const (
+	KrakenBaseURL = "https://api.kraken.com"
+	krakenMaxBars = 720
+)
+
+// krakenIntervals - периоды, которые Kraken отдаёт напрямую, от крупного к мелкому.
+var krakenIntervals = []time.Duration{
+	7 * 24 * time.Hour, 24 * time.Hour, 4 * time.Hour, time.Hour,
+	30 * time.Minute, 15 * time.Minute, 5 * time.Minute, time.Minute,
+}
+
+// krakenAssets - обозначения активов Kraken, отличающиеся от общепринятых.
+var krakenAssets = map[string]string{
+	"BTC":  "XBT",
+	"DOGE": "XDG",
+}
+
+// FeederKraken загружает свечи из Kraken-совместимого API /0/public/OHLC.
+type FeederKraken struct {
+	http *httpClient
+}
+
+// NewFeederKraken создаёт фидер Kraken, пустой baseURL - публичный API.
+func NewFeederKraken(baseURL string) *FeederKraken {
+	if baseURL == "" {
+		baseURL = KrakenBaseURL
+	}
+	return &FeederKraken{http: newHTTPClient(baseURL)}
+}
+
+type krakenResponse struct {
+	Error  []string                   `json:"error"`
+	Result map[string]json.RawMessage `json:"result"`
+}
+
+func (f *FeederKraken) GetQuote(ctx context.Context, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
+	start, end, err := utils.ParseDateRange(startDate, endDate, time.UTC)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+	if start.IsZero() {
+		return quote.Quote{}, fmt.Errorf("start date is required")
+	}
+	if end.IsZero() || end.After(time.Now()) {
+		end = time.Now()
+	}
+	pair, err := krakenPair(symbol)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+	// недельные свечи Kraken начинаются в четверг, поэтому неделю собираем из дней
+	step := nativeGranularity(krakenIntervals, period)
+	if period == quote.Weekly {
+		step = 24 * time.Hour
+	}
+
+	q := quote.NewQuote(symbol, 0)
+	since := start.Unix()
+	var earliest time.Time
+	for {
+		query := url.Values{}
+		query.Set("pair", pair)
+		query.Set("interval", strconv.Itoa(int(step.Minutes())))
+		query.Set("since", strconv.FormatInt(since, 10))
+
+		rows, last, err := f.fetchPage(ctx, query)
+		if err != nil {
+			return quote.Quote{}, err
+		}
+		added := false
+		for _, row := range rows {
+			// [time, open, high, low, close, vwap, volume, count]
+			if len(row) < 7 {
+				return quote.Quote{}, fmt.Errorf("kraken: unexpected OHLC row %v", row)
+			}
+			var vals [7]float64
+			for k := range vals {
+				if vals[k], err = parseFloatField(row[k]); err != nil {
+					return quote.Quote{}, fmt.Errorf("kraken: %w", err)
+				}
+			}
+			date := time.Unix(int64(vals[0]), 0).UTC()
+			if earliest.IsZero() || date.Before(earliest) {
+				earliest = date
+			}
+			if date.Before(start) || !date.Before(end) {
+				continue
+			}
+			if n := len(q.Date); n > 0 && !date.After(q.Date[n-1]) {
+				continue
+			}
+			q.Date = append(q.Date, date)
+			q.Open = append(q.Open, vals[1])
+			q.High = append(q.High, vals[2])
+			q.Low = append(q.Low, vals[3])
+			q.Close = append(q.Close, vals[4])
+			q.Volume = append(q.Volume, vals[6])
+			added = true
+		}
+		// страница без новых баров - конец данных
+		if !added || last <= since || time.Unix(last, 0).After(end) {
+			break
+		}
+		since = last
+		if err := waitPage(ctx); err != nil {
+			return quote.Quote{}, err
+		}
+	}
+
+	// Kraken отдаёт не больше 720 последних свечей, более ранняя часть диапазона недоступна
+	if !earliest.IsZero() && earliest.Sub(start) >= step {
+		return quote.Quote{}, fmt.Errorf("kraken: %s history for %s starts at %s, after requested start %s (only the last %d candles are served)",
+			step, symbol, earliest.Format("2006-01-02 15:04"), start.Format("2006-01-02 15:04"), krakenMaxBars)
+	}
+
+	if step == utils.PeriodDuration(period) {
+		return q, nil
+	}
+	return resample.Resample(q, period, resample.Options{Location: time.UTC, Base: step})
+}
+
+// Periods возвращает периоды, которые собираются из интервалов Kraken.
+func (f *FeederKraken) Periods() ([]quote.Period, error) {
+	return buildablePeriods(nil, krakenIntervals...), nil
+}
+
+func (f *FeederKraken) fetchPage(ctx context.Context, query url.Values) (rows [][]any, last int64, err error) {
+	var resp krakenResponse
+	if err := f.http.getJSON(ctx, "/0/public/OHLC", query, &resp); err != nil {
+		return nil, 0, fmt.Errorf("kraken: %w", err)
+	}
+	if len(resp.Error) > 0 {
+		return nil, 0, krakenError(resp.Error)
+	}
+	for key, raw := range resp.Result {
+		if key == "last" {
+			if err := json.Unmarshal(raw, &last); err != nil {
+				return nil, 0, fmt.Errorf("kraken: decode last: %w", err)
+			}
+			continue
+		}
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, 0, fmt.Errorf("kraken: decode %s: %w", key, err)
+		}
+	}
+	return rows, last, nil
+}
+
+// krakenError переводит ошибки из тела ответа в StatusError, чтобы повторять временные.
+func krakenError(errs []string) error {
+	msg := strings.Join(errs, "; ")
+	status := http.StatusBadRequest
+	if strings.Contains(msg, "Rate limit") || strings.Contains(msg, "Too many requests") {
+		status = http.StatusTooManyRequests
+	} else if strings.HasPrefix(msg, "EService:") {
+		status = http.StatusServiceUnavailable
+	}
+	return fmt.Errorf("kraken: %w", &StatusError{StatusCode: status, Body: msg})
+}
+
+// krakenPair приводит BTC-USD к XBTUSD.
+func krakenPair(symbol string) (string, error) {
+	base, quoteCcy, err := splitSymbol(symbol)
+	if err != nil {
+		return "", err
+	}
+	if alias, ok := krakenAssets[base]; ok {
+		base = alias
+	}
+	if alias, ok := krakenAssets[quoteCcy]; ok {
+		quoteCcy = alias
+	}
+	return base + quoteCcy, nil
+}
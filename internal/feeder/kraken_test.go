@@ -0,0 +1,191 @@
+package feeder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// krakenServer хранит историю от first до last и, как Kraken, отдаёт бары запрошенного
+// интервала страницами не больше pageSize баров, начиная с since.
+type krakenServer struct {
+	first, last time.Time
+	pageSize    int
+	requests    []url.Values
+	errors      []string
+}
+
+func (k *krakenServer) start(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		k.requests = append(k.requests, query)
+		if len(k.errors) > 0 {
+			writeJSON(t, w, map[string]any{"error": k.errors})
+			return
+		}
+		minutes, _ := strconv.Atoi(query.Get("interval"))
+		step := time.Duration(minutes) * time.Minute
+		since, _ := strconv.ParseInt(query.Get("since"), 10, 64)
+		from := time.Unix(since, 0).UTC()
+		if from.Before(k.first) {
+			from = k.first
+		}
+		rows := [][]any{}
+		var lastTime int64
+		for d := from.Truncate(step); !d.After(k.last) && len(rows) < k.pageSize; d = d.Add(step) {
+			price := strconv.FormatFloat(100+d.Sub(k.first).Hours(), 'f', 2, 64)
+			rows = append(rows, []any{d.Unix(), price, price, price, price, price, "2.0", 5})
+			lastTime = d.Unix()
+		}
+		writeJSON(t, w, map[string]any{
+			"error":  []string{},
+			"result": map[string]any{query.Get("pair"): rows, "last": lastTime},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestKrakenPagination(t *testing.T) {
+	k := &krakenServer{
+		first:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		last:     time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+		pageSize: 20,
+	}
+	q, err := NewFeederKraken(k.start(t).URL).GetQuote(context.Background(), "BTC-USD", "2024-01-01", "2024-01-02", quote.Min60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Date) != 48 {
+		t.Fatalf("got %d bars, want 48", len(q.Date))
+	}
+	if len(k.requests) < 3 {
+		t.Errorf("got %d requests, want pagination", len(k.requests))
+	}
+	if got := k.requests[0].Get("pair"); got != "XBTUSD" {
+		t.Errorf("pair = %q, want XBTUSD", got)
+	}
+	if got := k.requests[0].Get("interval"); got != "60" {
+		t.Errorf("interval = %q, want 60", got)
+	}
+	if q.Volume[0] != 2 {
+		t.Errorf("volume = %v, want 2", q.Volume[0])
+	}
+}
+
+func TestKrakenIntervals(t *testing.T) {
+	tests := []struct {
+		period   quote.Period
+		interval string
+		bars     int
+	}{
+		{quote.Min5, "5", 288},
+		{quote.Hour2, "60", 12},
+		{quote.Hour4, "240", 6},
+		{quote.Daily, "1440", 1},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			k := &krakenServer{
+				first:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				last:     time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+				pageSize: 5000,
+			}
+			q, err := NewFeederKraken(k.start(t).URL).GetQuote(context.Background(), "ETH-EUR", "2024-01-02", "2024-01-02", tt.period)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := k.requests[0].Get("interval"); got != tt.interval {
+				t.Errorf("interval = %q, want %q", got, tt.interval)
+			}
+			if len(q.Date) != tt.bars {
+				t.Errorf("got %d bars, want %d", len(q.Date), tt.bars)
+			}
+		})
+	}
+}
+
+func TestKrakenWeeklyFromDays(t *testing.T) {
+	k := &krakenServer{
+		first:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		last:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		pageSize: 720,
+	}
+	q, err := NewFeederKraken(k.start(t).URL).GetQuote(context.Background(), "BTC-USD", "2024-01-01", "2024-01-28", quote.Weekly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := k.requests[0].Get("interval"); got != "1440" {
+		t.Errorf("interval = %q, want daily candles", got)
+	}
+	if len(q.Date) != 4 {
+		t.Fatalf("got %d weeks, want 4", len(q.Date))
+	}
+	for i, d := range q.Date {
+		if d.Weekday() != time.Monday || q.Volume[i] != 14 {
+			t.Errorf("week %d starts %v with volume %v, want Monday and 14", i, d, q.Volume[i])
+		}
+	}
+}
+
+func TestKrakenHistoryGap(t *testing.T) {
+	// Kraken хранит только последние 720 свечей: история начинается позже запрошенной даты
+	k := &krakenServer{
+		first:    time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		last:     time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC),
+		pageSize: 720,
+	}
+	_, err := NewFeederKraken(k.start(t).URL).GetQuote(context.Background(), "BTC-USD", "2024-01-01", "2024-01-20", quote.Min60)
+	if err == nil || !strings.Contains(err.Error(), "2024-01-10") {
+		t.Fatalf("err = %v, want history gap error", err)
+	}
+}
+
+func TestKrakenErrors(t *testing.T) {
+	tests := []struct {
+		errors    []string
+		status    int
+		transient bool
+	}{
+		{[]string{"EQuery:Unknown asset pair"}, http.StatusBadRequest, false},
+		{[]string{"EAPI:Rate limit exceeded"}, http.StatusTooManyRequests, true},
+		{[]string{"EService:Unavailable"}, http.StatusServiceUnavailable, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.errors[0], func(t *testing.T) {
+			k := &krakenServer{errors: tt.errors}
+			_, err := NewFeederKraken(k.start(t).URL).GetQuote(context.Background(), "BTC-USD", "2024-01-01", "2024-01-02", quote.Min60)
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+				t.Fatalf("err = %v, want status %d", err, tt.status)
+			}
+			if IsTransient(err) != tt.transient {
+				t.Errorf("IsTransient = %v, want %v", !tt.transient, tt.transient)
+			}
+		})
+	}
+}
+
+func TestKrakenPair(t *testing.T) {
+	tests := []struct {
+		symbol, want string
+	}{
+		{"BTC-USD", "XBTUSD"},
+		{"DOGE/EUR", "XDGEUR"},
+		{"ETHUSDT", "ETHUSDT"},
+		{"eth-btc", "ETHXBT"},
+	}
+	for _, tt := range tests {
+		if got, err := krakenPair(tt.symbol); err != nil || got != tt.want {
+			t.Errorf("krakenPair(%q) = %q, %v, want %q", tt.symbol, got, err, tt.want)
+		}
+	}
+}
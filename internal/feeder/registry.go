@@ -0,0 +1,324 @@
+package feeder
+
+import (
+	"context"
+	"fmt"
+	"main/internal/resample"
+	"main/internal/utils"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/markcheno/go-quote"
+)
+
+// Env возвращает значение настройки по имени переменной окружения.
+type Env func(key string) string
+
+// SourceEnv ищет настройки источника name сначала в FEEDER_<NAME>_<KEY>, затем в общей FEEDER_<KEY>.
+// Например, для источника local ключ FEEDER_CSV_PATH читается из FEEDER_LOCAL_CSV_PATH.
+func SourceEnv(name string) Env {
+	prefix := "FEEDER_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+	return func(key string) string {
+		if rest, ok := strings.CutPrefix(key, "FEEDER_"); ok {
+			if v := os.Getenv(prefix + rest); v != "" {
+				return v
+			}
+		}
+		return os.Getenv(key)
+	}
+}
+
+// Factory создаёт Feeder по настройкам источника.
+type Factory func(env Env) (Feeder, error)
+
+var factories = map[string]Factory{}
+
+// Register делает тип фидера доступным для настройки через FEEDERS.
+func Register(typ string, factory Factory) {
+	factories[typ] = factory
+}
+
+// Types возвращает зарегистрированные типы фидеров.
+func Types() []string {
+	types := make([]string, 0, len(factories))
+	for t := range factories {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func init() {
+	coinbase := func(env Env) (Feeder, error) {
+		return NewFeederApiCoinbase(env("FEEDER_COINBASE_URL")), nil
+	}
+	Register("api", coinbase)
+	Register("coinbase", coinbase)
+	Register("binance", func(env Env) (Feeder, error) {
+		return NewFeederBinance(env("FEEDER_BINANCE_URL")), nil
+	})
+	Register("kraken", func(env Env) (Feeder, error) {
+		return NewFeederKraken(env("FEEDER_KRAKEN_URL")), nil
+	})
+	Register("json", func(env Env) (Feeder, error) {
+		return NewFeederJSONFile(JSONDataDirFromEnv(env)), nil
+	})
+	Register("csv", func(env Env) (Feeder, error) {
+		cfg, err := CSVConfigFromEnv(env)
+		if err != nil {
+			return nil, err
+		}
+		return NewFeederCSVFile(cfg), nil
+	})
+	Register("synthetic", func(env Env) (Feeder, error) {
+		cfg, err := SyntheticConfigFromEnv(env)
+		if err != nil {
+			return nil, err
+		}
+		return NewFeederSynthetic(cfg), nil
+	})
+}
+
+// Describer - необязательный интерфейс фидера для списка доступных символов.
+// nil означает, что фидер принимает любой символ.
+type Describer interface {
+	Symbols() ([]string, error)
+}
+
+// PeriodLister - необязательный интерфейс фидера для списка периодов, которые он отдаёт.
+// Фидер без него считается поддерживающим все периоды.
+type PeriodLister interface {
+	Periods() ([]quote.Period, error)
+}
+
+type Source struct {
+	Name   string
+	Type   string
+	Feeder Feeder // фидер с повторами и кэшем
+	base   Feeder
+	cache  *FeederCache
+}
+
+type SourceInfo struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Default bool     `json:"default"`
+	Cached  bool     `json:"cached"`
+	Symbols []string `json:"symbols"` // null - любой символ
+	Periods []string `json:"periods"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Registry хранит именованные источники данных. Первый добавленный источник - источник по умолчанию.
+type Registry struct {
+	sources map[string]*Source
+	order   []string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{sources: make(map[string]*Source)}
+}
+
+// NewRegistryFromEnv создаёт источники из FEEDERS=имя=тип,имя=тип.
+// Без FEEDERS используется один источник типа FEEDER (по умолчанию api).
+func NewRegistryFromEnv() (*Registry, error) {
+	spec := os.Getenv("FEEDERS")
+	if spec == "" {
+		typ := strings.ToLower(os.Getenv("FEEDER"))
+		if typ == "" {
+			typ = "api"
+		}
+		spec = typ + "=" + typ
+	}
+
+	r := NewRegistry()
+	for _, entry := range strings.Split(spec, ",") {
+		name, typ, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			// допускается короткая запись: FEEDERS=api,json
+			typ = name
+		}
+		name, typ = strings.TrimSpace(name), strings.ToLower(strings.TrimSpace(typ))
+		if err := r.Add(name, typ, SourceEnv(name)); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Add создаёт источник name типа typ, оборачивая его повторами и, если задан FEEDER_CACHE_DIR, дисковым кэшем.
+func (r *Registry) Add(name, typ string, env Env) error {
+	if name == "" {
+		return fmt.Errorf("feeder source name is empty")
+	}
+	if _, exists := r.sources[name]; exists {
+		return fmt.Errorf("feeder source %q is configured twice", name)
+	}
+	factory, ok := factories[typ]
+	if !ok {
+		return fmt.Errorf("unknown feeder type %q for source %q, available: %s", typ, name, strings.Join(Types(), ", "))
+	}
+
+	base, err := factory(env)
+	if err != nil {
+		return fmt.Errorf("feeder source %q: %w", name, err)
+	}
+	retryCfg, err := RetryConfigFromEnv(env)
+	if err != nil {
+		return fmt.Errorf("feeder source %q: %w", name, err)
+	}
+
+	src := &Source{Name: name, Type: typ, base: base, Feeder: NewFeederRetry(base, retryCfg)}
+	if dir := env("FEEDER_CACHE_DIR"); dir != "" {
+		src.cache = NewFeederCache(src.Feeder, filepath.Join(dir, name))
+		src.Feeder = src.cache
+	}
+
+	r.sources[name] = src
+	r.order = append(r.order, name)
+	return nil
+}
+
+// Get возвращает источник по имени, пустое имя - источник по умолчанию.
+func (r *Registry) Get(name string) (*Source, error) {
+	if name == "" {
+		if len(r.order) == 0 {
+			return nil, fmt.Errorf("no feeder sources configured")
+		}
+		name = r.order[0]
+	}
+	src, ok := r.sources[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown feeder source %q", name)
+	}
+	return src, nil
+}
+
+// GetQuote загружает котировки из источника source.
+func (r *Registry) GetQuote(ctx context.Context, source, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
+	src, err := r.Get(source)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+	return src.Feeder.GetQuote(ctx, symbol, startDate, endDate, period)
+}
+
+func (r *Registry) Info() []SourceInfo {
+	infos := make([]SourceInfo, 0, len(r.order))
+	for i, name := range r.order {
+		src := r.sources[name]
+		info := SourceInfo{
+			Name:    name,
+			Type:    src.Type,
+			Default: i == 0,
+			Cached:  src.cache != nil,
+		}
+		periods := utils.Periods
+		if l, ok := src.base.(PeriodLister); ok {
+			var err error
+			if periods, err = l.Periods(); err != nil {
+				info.Error = err.Error()
+			}
+		}
+		info.Periods = make([]string, len(periods))
+		for k, p := range periods {
+			info.Periods[k] = string(p)
+		}
+		if d, ok := src.base.(Describer); ok {
+			symbols, err := d.Symbols()
+			if err != nil {
+				info.Error = err.Error()
+			}
+			info.Symbols = symbols
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// buildablePeriods возвращает периоды utils.Periods, которые отдаются как есть (exact)
+// или собираются из баров одного из шагов bases.
+func buildablePeriods(exact []quote.Period, bases ...time.Duration) []quote.Period {
+	var out []quote.Period
+	for _, p := range utils.Periods {
+		ok := slices.Contains(exact, p)
+		for _, b := range bases {
+			ok = ok || resample.CanResample(b, p)
+		}
+		if ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (r *Registry) Register(router gin.IRouter) {
+	router.GET("feeders", r.GetFeeders)
+	router.GET("cache/stats", r.GetCacheStats)
+	router.POST("cache/purge", r.PurgeCache)
+}
+
+func (r *Registry) GetFeeders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"feeders": r.Info(),
+	})
+}
+
+func (r *Registry) GetCacheStats(c *gin.Context) {
+	stats := make(map[string]CacheStats)
+	for _, name := range r.order {
+		src := r.sources[name]
+		if src.cache == nil {
+			continue
+		}
+		s, err := src.cache.Stats()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		stats[name] = s
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+func (r *Registry) PurgeCache(c *gin.Context) {
+	var req struct {
+		Source   string `json:"source"`
+		Symbol   string `json:"symbol"`
+		Interval string `json:"interval"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var period quote.Period
+	if req.Interval != "" {
+		period = utils.ParsePeriod(req.Interval)
+	}
+
+	names := r.order
+	if req.Source != "" {
+		names = []string{req.Source}
+	}
+	for _, name := range names {
+		src, err := r.Get(name)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if src.cache == nil {
+			continue
+		}
+		if err := src.cache.Purge(req.Symbol, period); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
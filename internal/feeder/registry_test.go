@@ -0,0 +1,54 @@
+package feeder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+func TestInfoPeriods(t *testing.T) {
+	dir := t.TempDir()
+	q := quote.NewQuote("ETH-USD", 0)
+	for i := 0; i < 48; i++ {
+		q.Date = append(q.Date, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i)*4*time.Hour))
+		q.Open = append(q.Open, 1)
+		q.High = append(q.High, 1)
+		q.Low = append(q.Low, 1)
+		q.Close = append(q.Close, 1)
+		q.Volume = append(q.Volume, 1)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ETH-USD.json"), []byte(q.JSON(true)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRegistry()
+	env := func(key string) string {
+		if key == "FEEDER_JSON_DIR" {
+			return dir
+		}
+		return ""
+	}
+	for _, typ := range []string{"json", "binance", "kraken"} {
+		if err := r.Add(typ, typ, env); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string][]string{
+		"json":    {"4h", "8h", "12h", "d", "3d", "w", "m"},
+		"binance": {"60", "3m", "300", "900", "1800", "3600", "2h", "4h", "6h", "8h", "12h", "d", "3d", "w", "m"},
+		"kraken":  {"60", "3m", "300", "900", "1800", "3600", "2h", "4h", "6h", "8h", "12h", "d", "3d", "w", "m"},
+	}
+	for _, info := range r.Info() {
+		if info.Error != "" {
+			t.Errorf("%s: %s", info.Name, info.Error)
+		}
+		if !reflect.DeepEqual(info.Periods, want[info.Name]) {
+			t.Errorf("%s periods = %v, want %v", info.Name, info.Periods, want[info.Name])
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package feeder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// StatusError - ответ внешнего API с неуспешным HTTP-статусом.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// IsTransient сообщает, имеет ли смысл повторить запрос: сетевые ошибки, таймауты, 429 и 5xx.
+func IsTransient(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+type RetryConfig struct {
+	Attempts   int           // общее число попыток, включая первую
+	Backoff    time.Duration // пауза перед второй попыткой, далее удваивается
+	MaxBackoff time.Duration
+	Timeout    time.Duration // таймаут одной попытки, 0 - без ограничения
+}
+
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		Attempts:   3,
+		Backoff:    500 * time.Millisecond,
+		MaxBackoff: 10 * time.Second,
+		Timeout:    60 * time.Second,
+	}
+}
+
+// RetryConfigFromEnv читает FEEDER_RETRIES, FEEDER_RETRY_BACKOFF, FEEDER_RETRY_MAX_BACKOFF и FEEDER_TIMEOUT.
+func RetryConfigFromEnv(env Env) (RetryConfig, error) {
+	cfg := DefaultRetryConfig()
+
+	if v := env("FEEDER_RETRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("invalid FEEDER_RETRIES %q: must be a positive integer", v)
+		}
+		cfg.Attempts = n
+	}
+	durations := []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"FEEDER_RETRY_BACKOFF", &cfg.Backoff},
+		{"FEEDER_RETRY_MAX_BACKOFF", &cfg.MaxBackoff},
+		{"FEEDER_TIMEOUT", &cfg.Timeout},
+	}
+	for _, d := range durations {
+		v := env(d.name)
+		if v == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed < 0 {
+			return cfg, fmt.Errorf("invalid %s %q: must be a duration like 30s", d.name, v)
+		}
+		*d.dst = parsed
+	}
+	return cfg, nil
+}
+
+// FeederRetry повторяет запросы к вложенному Feeder при временных ошибках
+// с экспоненциальной паузой и ограничивает время каждой попытки.
+type FeederRetry struct {
+	inner  Feeder
+	config RetryConfig
+}
+
+func NewFeederRetry(inner Feeder, config RetryConfig) *FeederRetry {
+	if config.Attempts < 1 {
+		config.Attempts = 1
+	}
+	return &FeederRetry{inner: inner, config: config}
+}
+
+func (f *FeederRetry) GetQuote(ctx context.Context, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
+	var err error
+	for attempt := 0; attempt < f.config.Attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return quote.Quote{}, ctx.Err()
+			case <-time.After(f.backoff(attempt)):
+			}
+		}
+
+		var q quote.Quote
+		q, err = f.attempt(ctx, symbol, startDate, endDate, period)
+		if err == nil {
+			return q, nil
+		}
+		// отмена запроса клиентом не повторяется
+		if ctx.Err() != nil {
+			return quote.Quote{}, ctx.Err()
+		}
+		if !IsTransient(err) {
+			return quote.Quote{}, err
+		}
+	}
+	return quote.Quote{}, fmt.Errorf("giving up after %d attempts: %w", f.config.Attempts, err)
+}
+
+func (f *FeederRetry) attempt(ctx context.Context, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
+	if f.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.config.Timeout)
+		defer cancel()
+	}
+	return f.inner.GetQuote(ctx, symbol, startDate, endDate, period)
+}
+
+func (f *FeederRetry) backoff(attempt int) time.Duration {
+	d := f.config.Backoff << (attempt - 1)
+	if d <= 0 || (f.config.MaxBackoff > 0 && d > f.config.MaxBackoff) {
+		d = f.config.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	// случайная добавка, чтобы параллельные запросы не повторялись одновременно
+	return d + time.Duration(rand.Int63n(int64(d)/2+1))
+}
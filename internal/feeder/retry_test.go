@@ -0,0 +1,115 @@
+package feeder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// failing возвращает Feeder, который отвечает ошибками errs по очереди, а затем котировкой.
+func failing(calls *int, errs ...error) Feeder {
+	return feederFunc(func(context.Context, string, string, string, quote.Period) (quote.Quote, error) {
+		*calls++
+		if *calls <= len(errs) {
+			return quote.Quote{}, errs[*calls-1]
+		}
+		return quote.NewQuote("BTC-USD", 1), nil
+	})
+}
+
+func TestRetry(t *testing.T) {
+	timeout := &net.DNSError{Err: "timeout", IsTimeout: true}
+	unknown := errors.New("unknown symbol")
+	tests := []struct {
+		name  string
+		errs  []error
+		calls int
+		err   error // nil - котировка получена
+	}{
+		{"server error retried", []error{&StatusError{StatusCode: 503}, &StatusError{StatusCode: 502}}, 3, nil},
+		{"rate limit retried", []error{&StatusError{StatusCode: 429}}, 2, nil},
+		{"network error retried", []error{timeout}, 2, nil},
+		{"attempt timeout retried", []error{fmt.Errorf("read: %w", context.DeadlineExceeded)}, 2, nil},
+		{"gives up after attempts", []error{timeout, timeout, timeout}, 3, timeout},
+		{"client error not retried", []error{&StatusError{StatusCode: 404}}, 1, &StatusError{StatusCode: 404}},
+		{"no data not retried", []error{fmt.Errorf("%w for BTC-USD", ErrNoData)}, 1, ErrNoData},
+		{"permanent error not retried", []error{unknown}, 1, unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			f := NewFeederRetry(failing(&calls, tt.errs...), RetryConfig{Attempts: 3, Backoff: time.Millisecond})
+			q, err := f.GetQuote(context.Background(), "BTC-USD", "", "", quote.Min60)
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+			switch {
+			case tt.err == nil && (err != nil || len(q.Date) != 1):
+				t.Errorf("err = %v, bars = %d, want the quote", err, len(q.Date))
+			case tt.err != nil && err == nil:
+				t.Errorf("err = nil, want %v", tt.err)
+			case tt.err != nil && !errors.Is(err, tt.err) && err.Error() != tt.err.Error():
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRetryCancelStopsBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	inner := feederFunc(func(context.Context, string, string, string, quote.Period) (quote.Quote, error) {
+		calls++
+		cancel()
+		return quote.Quote{}, &StatusError{StatusCode: 503}
+	})
+	f := NewFeederRetry(inner, RetryConfig{Attempts: 3, Backoff: time.Hour})
+
+	start := time.Now()
+	_, err := f.GetQuote(ctx, "BTC-USD", "", "", quote.Min60)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if calls != 1 || time.Since(start) > time.Second {
+		t.Errorf("calls = %d after %s, want one call without waiting for the backoff", calls, time.Since(start))
+	}
+}
+
+func TestRetryCancelDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	calls := 0
+	f := NewFeederRetry(failing(&calls, &StatusError{StatusCode: 503}), RetryConfig{Attempts: 3, Backoff: time.Hour})
+
+	start := time.Now()
+	_, err := f.GetQuote(ctx, "BTC-USD", "", "", quote.Min60)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if calls != 1 || time.Since(start) > time.Second {
+		t.Errorf("calls = %d after %s, want the backoff to stop on cancellation", calls, time.Since(start))
+	}
+}
+
+func TestRetryConfigFromEnv(t *testing.T) {
+	env := func(values map[string]string) Env {
+		return func(key string) string { return values[key] }
+	}
+	cfg, err := RetryConfigFromEnv(env(map[string]string{"FEEDER_RETRIES": "5", "FEEDER_RETRY_BACKOFF": "2s"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Attempts != 5 || cfg.Backoff != 2*time.Second || cfg.Timeout != DefaultRetryConfig().Timeout {
+		t.Errorf("config = %+v", cfg)
+	}
+	for _, bad := range []map[string]string{{"FEEDER_RETRIES": "0"}, {"FEEDER_TIMEOUT": "-1s"}, {"FEEDER_RETRY_MAX_BACKOFF": "soon"}} {
+		if _, err := RetryConfigFromEnv(env(bad)); err == nil {
+			t.Errorf("%v: expected an error", bad)
+		}
+	}
+}
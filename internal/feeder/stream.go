@@ -0,0 +1,164 @@
+package feeder
+
+import (
+	"context"
+	"errors"
+	"main/internal/utils"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+const streamTimeFormat = "2006-01-02 15:04"
+
+// Candle - свеча из потока. Closed=false означает ещё формирующийся бар,
+// который будет прислан повторно с обновлёнными значениями.
+type Candle struct {
+	Symbol string       `json:"symbol"`
+	Period quote.Period `json:"period"`
+	Date   time.Time    `json:"date"`
+	Open   float64      `json:"open"`
+	High   float64      `json:"high"`
+	Low    float64      `json:"low"`
+	Close  float64      `json:"close"`
+	Volume float64      `json:"volume"`
+	Closed bool         `json:"closed"`
+}
+
+// Streamer присылает в out свечи symbol/period, начиная с бара после since.
+// Stream блокируется до отмены ctx или окончания данных и не закрывает out.
+type Streamer interface {
+	Stream(ctx context.Context, symbol string, period quote.Period, since time.Time, out chan<- Candle) error
+}
+
+func candleAt(q quote.Quote, i int, period quote.Period, closed bool) Candle {
+	return Candle{
+		Symbol: q.Symbol,
+		Period: period,
+		Date:   q.Date[i],
+		Open:   q.Open[i],
+		High:   q.High[i],
+		Low:    q.Low[i],
+		Close:  q.Close[i],
+		Volume: q.Volume[i],
+		Closed: closed,
+	}
+}
+
+func send(ctx context.Context, out chan<- Candle, c Candle) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case out <- c:
+		return nil
+	}
+}
+
+// StreamPolling периодически опрашивает Feeder и присылает новые и обновлённые бары.
+type StreamPolling struct {
+	feeder   Feeder
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewStreamPolling(feeder Feeder, interval time.Duration) *StreamPolling {
+	return &StreamPolling{feeder: feeder, interval: interval, now: time.Now}
+}
+
+func (s *StreamPolling) Stream(ctx context.Context, symbol string, period quote.Period, since time.Time, out chan<- Candle) error {
+	step := utils.PeriodDuration(period)
+	if since.IsZero() {
+		since = s.now().Add(-3 * step)
+	}
+	lastClosed := since
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		now := s.now()
+		// запрашиваем с последнего закрытого бара, чтобы заметить его окончательные значения
+		from := lastClosed.UTC().Format(streamTimeFormat)
+		to := now.Add(step).UTC().Format(streamTimeFormat)
+		q, err := s.feeder.GetQuote(ctx, symbol, from, to, period)
+		// ErrNoData - новых баров ещё нет, ждём следующего опроса
+		if err != nil && (ctx.Err() != nil || !IsTransient(err) && !errors.Is(err, ErrNoData)) {
+			return err
+		}
+
+		for i := range q.Date {
+			if !q.Date[i].After(lastClosed) {
+				continue
+			}
+			closed := !q.Date[i].Add(step).After(now)
+			if err := send(ctx, out, candleAt(q, i, period, closed)); err != nil {
+				return err
+			}
+			if closed {
+				lastClosed = q.Date[i]
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// StreamReplay проигрывает исторические бары из Feeder (например, файла) с паузой delay.
+// Каждый бар сначала приходит формирующимся (только цена открытия), затем закрытым.
+type StreamReplay struct {
+	feeder    Feeder
+	startDate string
+	endDate   string
+	delay     time.Duration
+}
+
+func NewStreamReplay(feeder Feeder, startDate, endDate string, delay time.Duration) *StreamReplay {
+	return &StreamReplay{feeder: feeder, startDate: startDate, endDate: endDate, delay: delay}
+}
+
+func (s *StreamReplay) Stream(ctx context.Context, symbol string, period quote.Period, since time.Time, out chan<- Candle) error {
+	startDate := s.startDate
+	if !since.IsZero() {
+		startDate = since.UTC().Format(streamTimeFormat)
+	}
+	q, err := s.feeder.GetQuote(ctx, symbol, startDate, s.endDate, period)
+	if err != nil {
+		return err
+	}
+
+	for i := range q.Date {
+		if !q.Date[i].After(since) {
+			continue
+		}
+		open := candleAt(q, i, period, false)
+		open.High, open.Low, open.Close, open.Volume = open.Open, open.Open, open.Open, 0
+		if err := send(ctx, out, open); err != nil {
+			return err
+		}
+		if err := sleep(ctx, s.delay/2); err != nil {
+			return err
+		}
+		if err := send(ctx, out, candleAt(q, i, period, true)); err != nil {
+			return err
+		}
+		if err := sleep(ctx, s.delay-s.delay/2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
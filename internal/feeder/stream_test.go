@@ -0,0 +1,69 @@
+package feeder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// feederFunc позволяет задать Feeder функцией.
+type feederFunc func(ctx context.Context, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error)
+
+func (f feederFunc) GetQuote(ctx context.Context, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
+	return f(ctx, symbol, startDate, endDate, period)
+}
+
+func TestStreamPollingSkipsEmptyPolls(t *testing.T) {
+	polls := 0
+	inner := feederFunc(func(context.Context, string, string, string, quote.Period) (quote.Quote, error) {
+		polls++
+		if polls < 3 {
+			return quote.Quote{}, fmt.Errorf("%w for BTC-USD", ErrNoData)
+		}
+		q := quote.NewQuote("BTC-USD", 0)
+		q.Date = append(q.Date, at("2024-01-01 10:00"))
+		q.Open = append(q.Open, 1)
+		q.High = append(q.High, 2)
+		q.Low = append(q.Low, 1)
+		q.Close = append(q.Close, 2)
+		q.Volume = append(q.Volume, 1)
+		return q, nil
+	})
+	s := NewStreamPolling(inner, time.Millisecond)
+	s.now = func() time.Time { return at("2024-01-01 11:30") }
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	out := make(chan Candle, 1)
+	done := make(chan error, 1)
+	go func() { done <- s.Stream(ctx, "BTC-USD", quote.Min60, at("2024-01-01 09:00"), out) }()
+
+	select {
+	case c := <-out:
+		if !c.Date.Equal(at("2024-01-01 10:00")) || !c.Closed {
+			t.Errorf("candle = %+v, want closed 10:00 bar", c)
+		}
+	case err := <-done:
+		t.Fatalf("stream stopped on an empty poll: %v", err)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestStreamPollingStopsOnPermanentError(t *testing.T) {
+	permanent := errors.New("unknown symbol")
+	inner := feederFunc(func(context.Context, string, string, string, quote.Period) (quote.Quote, error) {
+		return quote.Quote{}, permanent
+	})
+	s := NewStreamPolling(inner, time.Millisecond)
+	err := s.Stream(context.Background(), "BTC-USD", quote.Min60, time.Time{}, make(chan Candle))
+	if !errors.Is(err, permanent) {
+		t.Errorf("err = %v, want %v", err, permanent)
+	}
+}
@@ -0,0 +1,279 @@
+package feeder
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"main/internal/utils"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+const (
+	SyntheticGBM        = "gbm"        // геометрическое броуновское движение
+	SyntheticMeanRevert = "meanrevert" // процесс Орнштейна-Уленбека для логарифма цены
+	SyntheticRegime     = "regime"     // переключение между трендом и флэтом
+	SyntheticJump       = "jump"       // диффузия со скачками (Мертон)
+
+	syntheticMaxBars = 200_000
+	syntheticYear    = 365 * 24 * time.Hour
+)
+
+// syntheticEpoch - начало всех синтетических рядов (понедельник): бары выровнены по сетке от этой даты,
+// а цена бара не зависит от запрошенного диапазона.
+var syntheticEpoch = time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+
+// SyntheticConfig задаёт модель генерации. Drift и Volatility - годовые значения.
+type SyntheticConfig struct {
+	Model      string
+	Seed       int64
+	StartPrice float64
+	Drift      float64
+	Volatility float64
+
+	// meanrevert и regime: скорость возврата к стартовой цене в год
+	MeanReversion float64
+	// regime: вероятность смены режима на каждом баре
+	RegimeSwitch float64
+	// jump: среднее число скачков в год, средний размер и разброс скачка в долях цены
+	JumpIntensity float64
+	JumpMean      float64
+	JumpStd       float64
+}
+
+func DefaultSyntheticConfig() SyntheticConfig {
+	return SyntheticConfig{
+		Model:         SyntheticGBM,
+		Seed:          1,
+		StartPrice:    50000,
+		Drift:         0.2,
+		Volatility:    0.6,
+		MeanReversion: 5,
+		RegimeSwitch:  0.01,
+		JumpIntensity: 12,
+		JumpMean:      -0.02,
+		JumpStd:       0.05,
+	}
+}
+
+// SyntheticConfigFromEnv читает настройки из переменных окружения FEEDER_SYNTHETIC_*.
+func SyntheticConfigFromEnv(env Env) (SyntheticConfig, error) {
+	cfg := DefaultSyntheticConfig()
+
+	if v := env("FEEDER_SYNTHETIC_MODEL"); v != "" {
+		cfg.Model = strings.ToLower(v)
+	}
+	if v := env("FEEDER_SYNTHETIC_SEED"); v != "" {
+		seed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid FEEDER_SYNTHETIC_SEED: %w", err)
+		}
+		cfg.Seed = seed
+	}
+	floats := []struct {
+		name string
+		dst  *float64
+	}{
+		{"FEEDER_SYNTHETIC_PRICE", &cfg.StartPrice},
+		{"FEEDER_SYNTHETIC_DRIFT", &cfg.Drift},
+		{"FEEDER_SYNTHETIC_VOLATILITY", &cfg.Volatility},
+		{"FEEDER_SYNTHETIC_MEAN_REVERSION", &cfg.MeanReversion},
+		{"FEEDER_SYNTHETIC_REGIME_SWITCH", &cfg.RegimeSwitch},
+		{"FEEDER_SYNTHETIC_JUMP_INTENSITY", &cfg.JumpIntensity},
+		{"FEEDER_SYNTHETIC_JUMP_MEAN", &cfg.JumpMean},
+		{"FEEDER_SYNTHETIC_JUMP_STD", &cfg.JumpStd},
+	}
+	for _, f := range floats {
+		v := env(f.name)
+		if v == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s: %w", f.name, err)
+		}
+		*f.dst = parsed
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c SyntheticConfig) Validate() error {
+	switch c.Model {
+	case SyntheticGBM, SyntheticMeanRevert, SyntheticRegime, SyntheticJump:
+	default:
+		return fmt.Errorf("unknown synthetic model %q", c.Model)
+	}
+	if c.StartPrice <= 0 {
+		return fmt.Errorf("synthetic start price must be positive")
+	}
+	if c.Volatility < 0 || c.JumpStd < 0 || c.JumpIntensity < 0 || c.MeanReversion < 0 {
+		return fmt.Errorf("synthetic volatility, jump and mean reversion parameters must not be negative")
+	}
+	if c.RegimeSwitch < 0 || c.RegimeSwitch > 1 {
+		return fmt.Errorf("synthetic regime switch probability must be in [0, 1]")
+	}
+	return nil
+}
+
+// FeederSynthetic генерирует OHLCV-ряды без сети. Ряд строится от syntheticEpoch,
+// поэтому одинаковые seed, символ и период дают одинаковую свечу на одну и ту же дату
+// при любом запрошенном диапазоне.
+type FeederSynthetic struct {
+	config SyntheticConfig
+}
+
+func NewFeederSynthetic(config SyntheticConfig) *FeederSynthetic {
+	return &FeederSynthetic{config: config}
+}
+
+func (f *FeederSynthetic) GetQuote(ctx context.Context, symbol, startDate, endDate string, period quote.Period) (quote.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return quote.Quote{}, err
+	}
+	if err := f.config.Validate(); err != nil {
+		return quote.Quote{}, err
+	}
+	start, end, err := utils.ParseDateRange(startDate, endDate, time.UTC)
+	if err != nil {
+		return quote.Quote{}, err
+	}
+	if start.IsZero() || end.IsZero() {
+		return quote.Quote{}, fmt.Errorf("synthetic feeder requires both start and end dates")
+	}
+
+	if start.Before(syntheticEpoch) {
+		return quote.Quote{}, fmt.Errorf("synthetic data starts at %s", syntheticEpoch.Format("2006-01-02"))
+	}
+
+	step := utils.PeriodDuration(period)
+	// первый бар сетки, начинающийся не раньше start
+	first := int((start.Sub(syntheticEpoch) + step - 1) / step)
+	bars := int(end.Sub(syntheticEpoch)/step) - first
+	if bars <= 0 {
+		return quote.Quote{}, fmt.Errorf("date range is shorter than one %s bar", period)
+	}
+	if bars > syntheticMaxBars {
+		return quote.Quote{}, fmt.Errorf("date range needs %d bars, limit is %d", bars, syntheticMaxBars)
+	}
+
+	return f.generate(symbol, step, first, bars), nil
+}
+
+// generate проходит ряд от эпохи и возвращает бары с номерами [first, first+bars).
+// Случайные числа каждого бара берутся из собственного seed по номеру бара, поэтому
+// для пропускаемых баров достаточно считать только цену.
+func (f *FeederSynthetic) generate(symbol string, step time.Duration, first, bars int) quote.Quote {
+	cfg := f.config
+	key := uint64(cfg.Seed ^ symbolSeed(symbol))
+	src := &barSource{}
+	rnd := rand.New(src)
+
+	dt := float64(step) / float64(syntheticYear)
+	sqrtDt := math.Sqrt(dt)
+	anchor := math.Log(cfg.StartPrice)
+	logPrice := anchor
+	trending := true
+
+	q := quote.NewQuote(symbol, bars)
+	for n := 0; n < first+bars; n++ {
+		src.state = key ^ uint64(n)*0x9e3779b97f4a7c15
+		prevLog := logPrice
+		z := rnd.NormFloat64()
+		vol := cfg.Volatility
+
+		switch cfg.Model {
+		case SyntheticGBM:
+			logPrice += (cfg.Drift-vol*vol/2)*dt + vol*sqrtDt*z
+		case SyntheticMeanRevert:
+			logPrice += cfg.MeanReversion*(anchor-logPrice)*dt + vol*sqrtDt*z
+		case SyntheticRegime:
+			if rnd.Float64() < cfg.RegimeSwitch {
+				trending = !trending
+			}
+			if trending {
+				logPrice += (cfg.Drift-vol*vol/2)*dt + vol*sqrtDt*z
+				anchor = logPrice
+			} else {
+				// во флэте цена тянется к уровню начала режима с меньшей волатильностью
+				vol *= 0.5
+				logPrice += cfg.MeanReversion*(anchor-logPrice)*dt + vol*sqrtDt*z
+			}
+		case SyntheticJump:
+			logPrice += (cfg.Drift-vol*vol/2)*dt + vol*sqrtDt*z
+			for n := poisson(rnd, cfg.JumpIntensity*dt); n > 0; n-- {
+				logPrice += math.Log1p(cfg.JumpMean + cfg.JumpStd*rnd.NormFloat64())
+			}
+		}
+		if math.IsNaN(logPrice) || math.IsInf(logPrice, 0) {
+			// скачок ниже -100% - оставляем цену на прежнем уровне
+			logPrice = prevLog
+		}
+		if n < first {
+			continue
+		}
+		i := n - first
+		open := math.Exp(prevLog)
+		closePrice := math.Exp(logPrice)
+
+		// тени свечи пропорциональны волатильности бара
+		barVol := vol * sqrtDt
+		high := math.Max(open, closePrice) * math.Exp(math.Abs(rnd.NormFloat64())*barVol/2)
+		low := math.Min(open, closePrice) * math.Exp(-math.Abs(rnd.NormFloat64())*barVol/2)
+
+		// объём растёт вместе с размером движения
+		move := 0.0
+		if barVol > 0 {
+			move = math.Abs(math.Log(closePrice/open)) / barVol
+		}
+		volume := 100 * math.Exp(0.3*rnd.NormFloat64()) * (1 + move)
+
+		q.Date[i] = syntheticEpoch.Add(time.Duration(n) * step)
+		q.Open[i] = open
+		q.High[i] = high
+		q.Low[i] = low
+		q.Close[i] = closePrice
+		q.Volume[i] = volume
+	}
+	return q
+}
+
+func symbolSeed(symbol string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(symbol))
+	return int64(h.Sum64())
+}
+
+// barSource - генератор splitmix64, состояние которого задаётся номером бара.
+type barSource struct {
+	state uint64
+}
+
+func (s *barSource) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *barSource) Int63() int64 { return int64(s.Uint64() >> 1) }
+
+func (s *barSource) Seed(seed int64) { s.state = uint64(seed) }
+
+// poisson - алгоритм Кнута, подходит для малых lambda.
+func poisson(rnd *rand.Rand, lambda float64) int {
+	if lambda <= 0 {
+		return 0
+	}
+	l := math.Exp(-lambda)
+	k := 0
+	for p := rnd.Float64(); p > l; p *= rnd.Float64() {
+		k++
+	}
+	return k
+}
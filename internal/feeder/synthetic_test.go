@@ -0,0 +1,73 @@
+package feeder
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+func TestSyntheticReproducible(t *testing.T) {
+	ctx := context.Background()
+	for _, model := range []string{SyntheticGBM, SyntheticMeanRevert, SyntheticRegime, SyntheticJump} {
+		t.Run(model, func(t *testing.T) {
+			cfg := DefaultSyntheticConfig()
+			cfg.Model = model
+
+			a, err := NewFeederSynthetic(cfg).GetQuote(ctx, "BTC-USD", "2024-01-01", "2024-01-10", quote.Min60)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := NewFeederSynthetic(cfg).GetQuote(ctx, "BTC-USD", "2024-01-01", "2024-01-10", quote.Min60)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(a, b) {
+				t.Fatal("same seed produced different candles")
+			}
+
+			// пересекающийся диапазон с другим началом даёт те же свечи на общих датах
+			c, err := NewFeederSynthetic(cfg).GetQuote(ctx, "BTC-USD", "2024-01-05 07:30", "2024-01-20", quote.Min60)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := time.Date(2024, 1, 5, 8, 0, 0, 0, time.UTC); !c.Date[0].Equal(want) {
+				t.Fatalf("first bar at %v, want %v", c.Date[0], want)
+			}
+			offset := 4*24 + 8
+			for i := offset; i < len(a.Date); i++ {
+				j := i - offset
+				if !a.Date[i].Equal(c.Date[j]) || a.Open[i] != c.Open[j] || a.High[i] != c.High[j] ||
+					a.Low[i] != c.Low[j] || a.Close[i] != c.Close[j] || a.Volume[i] != c.Volume[j] {
+					t.Fatalf("bar %v differs between overlapping ranges", a.Date[i])
+				}
+			}
+
+			cfg.Seed++
+			d, err := NewFeederSynthetic(cfg).GetQuote(ctx, "BTC-USD", "2024-01-01", "2024-01-10", quote.Min60)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reflect.DeepEqual(a.Close, d.Close) {
+				t.Error("different seeds produced identical candles")
+			}
+		})
+	}
+}
+
+func TestSyntheticCandlesAreConsistent(t *testing.T) {
+	q, err := NewFeederSynthetic(DefaultSyntheticConfig()).GetQuote(context.Background(), "ETH-USD", "2024-01-01", "2024-02-01", quote.Min15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range q.Date {
+		if q.High[i] < q.Open[i] || q.High[i] < q.Close[i] || q.Low[i] > q.Open[i] || q.Low[i] > q.Close[i] || q.Low[i] <= 0 {
+			t.Fatalf("bar %v: inconsistent OHLC %v %v %v %v", q.Date[i], q.Open[i], q.High[i], q.Low[i], q.Close[i])
+		}
+		if i > 0 && q.Open[i] != q.Close[i-1] {
+			t.Fatalf("bar %v opens at %v, previous close %v", q.Date[i], q.Open[i], q.Close[i-1])
+		}
+	}
+}
@@ -1,59 +1,320 @@
 package indicatorrsi
 
 import (
+	"encoding/json"
 	"fmt"
-	"os"
+	"main/internal/strategy"
+	"main/internal/utils"
+	"slices"
+	"strings"
 
-	"gopkg.in/yaml.v3"
+	"github.com/markcheno/go-quote"
 )
 
-var (
-	primaryPath  = "internal/indicator/rsi/config.yaml"
-	fallbackPath = "internal/indicator/rsi/config.default.yaml"
-)
+// Store хранит конфигурацию RSI: config.yaml, а при его отсутствии config.default.yaml.
+var Store = strategy.ConfigStore{
+	Primary:  "internal/indicator/rsi/config.yaml",
+	Fallback: "internal/indicator/rsi/config.default.yaml",
+}
+
+// maxVariants ограничивает число конфигураций, перебираемых оптимизатором.
+const maxVariants = 200000
 
 type Config struct {
 	// Индикаторы
 	RSILength     int `yaml:"rsi_length" json:"rsiLength"`          // длина RSI
 	EMASlowLength int `yaml:"ema_slow_length" json:"emaSlowLength"` // длина медленной EMA
+	EMAFastLength int `yaml:"ema_fast_length" json:"emaFastLength"` // длина быстрой EMA
 
 	// Уровни RSI
 	RSIBuyLevel  float64 `yaml:"rsi_buy_level" json:"rsiBuyLevel"`   // уровень входа
 	RSIExitLevel float64 `yaml:"rsi_exit_level" json:"rsiExitLevel"` // уровень выхода
 
 	MinBarsBetweenTrades int `yaml:"min_bars_between_trades" json:"minBarsBetweenTrades"` // минимальное количество баров
+	WarmupBars           int `yaml:"warmup_bars" json:"warmupBars"`                       // баров сверх самого длинного индикатора до анализа
+
+	// Устарело: при загрузке YAML и разборе JSON переносится в SellConditions.Threshold
+	CountSellSignals int `yaml:"count_sell_signals,omitempty" json:"count_sell_signals,omitempty"`
+
+	BuyConditions  Conditions `yaml:"buy_conditions" json:"buyConditions"`
+	SellConditions Conditions `yaml:"sell_conditions" json:"sellConditions"`
 
-	CountSellSignals int `yaml:"count_sell_signals" json:"count_sell_signals"`
+	HigherTimeframe HigherTimeframe `yaml:"higher_timeframe" json:"higherTimeframe"` // подтверждение трендом старшего периода
+
+	Backtest strategy.BacktestConfig `yaml:"backtest" json:"backtest"` // направления, издержки, размер позиций, выходы и цель оптимизации
+
+	Optimize OptimizeRanges `yaml:"optimize" json:"optimize"` // диапазоны перебора оптимизатора
 }
 
-func NewConfig() (*Config, error) {
-	var config Config
+// HigherTimeframe - фильтр по старшему периоду: например, входы на 1h только когда на 4h
+// быстрая EMA выше медленной. Используется последний закрытый к концу бара старший бар.
+type HigherTimeframe struct {
+	Enabled       bool   `yaml:"enabled" json:"enabled"`
+	Interval      string `yaml:"interval" json:"interval"`             // старший период в формате interval, например 4h
+	EMAFastLength int    `yaml:"ema_fast_length" json:"emaFastLength"` // длина быстрой EMA старшего периода
+	EMASlowLength int    `yaml:"ema_slow_length" json:"emaSlowLength"` // длина медленной EMA старшего периода
+	ConfirmBuy    bool   `yaml:"confirm_buy" json:"confirmBuy"`        // покупка только при быстрой EMA выше медленной
+	ConfirmSell   bool   `yaml:"confirm_sell" json:"confirmSell"`      // продажа только при быстрой EMA ниже медленной
+}
 
-	fileData, err := os.ReadFile(primaryPath)
-	if err != nil {
-		fileData, err = os.ReadFile(fallbackPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read both config.yaml and config.default.yaml: %w", err)
-		}
+// Condition - настройка отдельного условия сигнала.
+type Condition struct {
+	Enabled bool    `yaml:"enabled" json:"enabled"`
+	Weight  float64 `yaml:"weight" json:"weight"` // вклад выполненного условия в оценку сигнала
+}
+
+// Conditions - условия сигнала покупки или продажи. Сигнал возникает, когда сумма весов
+// выполненных включённых условий достигает Threshold; при весах 1 это правило "N из M".
+type Conditions struct {
+	Threshold float64 `yaml:"threshold" json:"threshold"`
+
+	EMACross Condition `yaml:"ema_cross" json:"emaCross"` // цена пересекает медленную EMA
+	RSICross Condition `yaml:"rsi_cross" json:"rsiCross"` // RSI пересекает уровень
+	EMATrend Condition `yaml:"ema_trend" json:"emaTrend"` // быстрая EMA выше (ниже) медленной
+	Cooldown Condition `yaml:"cooldown" json:"cooldown"`  // прошло MinBarsBetweenTrades баров с прошлого сигнала
+}
+
+type IntRange struct {
+	Min  int `yaml:"min" json:"min"`
+	Max  int `yaml:"max" json:"max"`
+	Step int `yaml:"step" json:"step"`
+}
+
+type FloatRange struct {
+	Min  float64 `yaml:"min" json:"min"`
+	Max  float64 `yaml:"max" json:"max"`
+	Step float64 `yaml:"step" json:"step"`
+}
+
+type OptimizeRanges struct {
+	RSILength     IntRange   `yaml:"rsi_length" json:"rsiLength"`
+	EMASlowLength IntRange   `yaml:"ema_slow_length" json:"emaSlowLength"`
+	EMAFastLength IntRange   `yaml:"ema_fast_length" json:"emaFastLength"`
+	RSIBuyLevel   FloatRange `yaml:"rsi_buy_level" json:"rsiBuyLevel"`
+	RSIExitLevel  FloatRange `yaml:"rsi_exit_level" json:"rsiExitLevel"`
+}
+
+// DefaultConfig возвращает значения, которые действуют для ключей, отсутствующих в YAML.
+func DefaultConfig() Config {
+	buy := Conditions{
+		Threshold: 4,
+		EMACross:  Condition{Enabled: true, Weight: 1},
+		RSICross:  Condition{Enabled: true, Weight: 1},
+		EMATrend:  Condition{Enabled: true, Weight: 1},
+		Cooldown:  Condition{Enabled: true, Weight: 1},
+	}
+	sell := buy
+	sell.Threshold = 3
+	return Config{
+		RSILength:            14,
+		EMASlowLength:        50,
+		EMAFastLength:        20,
+		RSIBuyLevel:          30,
+		RSIExitLevel:         70,
+		MinBarsBetweenTrades: 1,
+		WarmupBars:           10,
+		BuyConditions:        buy,
+		SellConditions:       sell,
+		HigherTimeframe: HigherTimeframe{
+			Interval:      "4h",
+			EMAFastLength: 20,
+			EMASlowLength: 50,
+			ConfirmBuy:    true,
+		},
+		Backtest: strategy.DefaultBacktestConfig(),
+		Optimize: OptimizeRanges{
+			RSILength:     IntRange{Min: 7, Max: 21, Step: 2},
+			EMASlowLength: IntRange{Min: 30, Max: 200, Step: 10},
+			EMAFastLength: IntRange{Min: 20, Max: 20, Step: 1},
+			RSIBuyLevel:   FloatRange{Min: 20, Max: 40, Step: 2},
+			RSIExitLevel:  FloatRange{Min: 60, Max: 80, Step: 2},
+		},
 	}
+}
 
-	if err := yaml.Unmarshal(fileData, &config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+func NewConfig() (*Config, error) {
+	config := DefaultConfig()
+	if err := Store.Load(&config); err != nil {
+		return nil, err
+	}
+	config.migrate()
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rsi config: %w", err)
 	}
 	return &config, nil
 }
 
+// migrate переносит устаревшие поля в актуальные.
+func (c *Config) migrate() {
+	if c.CountSellSignals > 0 {
+		c.SellConditions.Threshold = float64(c.CountSellSignals)
+		c.CountSellSignals = 0
+	}
+}
+
+// UnmarshalJSON разбирает конфигурацию из запросов API с тем же переносом
+// устаревших полей, что и при загрузке YAML.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plain Config
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+	c.migrate()
+	return nil
+}
+
 func (c *Config) SaveConfig() error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	return Store.Save(c)
+}
 
-	data, err := yaml.Marshal(c)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+func (c *Config) BacktestConfig() strategy.BacktestConfig {
+	return c.Backtest
+}
+
+func (c *Config) Validate() error {
+	if c.RSILength < 2 {
+		return fmt.Errorf("rsiLength must be at least 2, got %d", c.RSILength)
+	}
+	if c.EMASlowLength < 2 {
+		return fmt.Errorf("emaSlowLength must be at least 2, got %d", c.EMASlowLength)
+	}
+	if c.EMAFastLength < 2 {
+		return fmt.Errorf("emaFastLength must be at least 2, got %d", c.EMAFastLength)
+	}
+	if err := checkLevel("rsiBuyLevel", c.RSIBuyLevel); err != nil {
+		return err
 	}
+	if err := checkLevel("rsiExitLevel", c.RSIExitLevel); err != nil {
+		return err
+	}
+	if c.MinBarsBetweenTrades < 0 {
+		return fmt.Errorf("minBarsBetweenTrades must not be negative, got %d", c.MinBarsBetweenTrades)
+	}
+	if c.WarmupBars < 0 {
+		return fmt.Errorf("warmupBars must not be negative, got %d", c.WarmupBars)
+	}
+	if c.CountSellSignals < 0 {
+		return fmt.Errorf("count_sell_signals is deprecated, use sellConditions.threshold")
+	}
+	if err := c.BuyConditions.Validate("buyConditions"); err != nil {
+		return err
+	}
+	if err := c.SellConditions.Validate("sellConditions"); err != nil {
+		return err
+	}
+	if err := c.HigherTimeframe.Validate(); err != nil {
+		return err
+	}
+	if err := c.Backtest.Validate(); err != nil {
+		return err
+	}
+	return c.Optimize.Validate()
+}
+
+func (h HigherTimeframe) Validate() error {
+	if !h.Enabled {
+		return nil
+	}
+	if !slices.Contains(utils.Periods, quote.Period(strings.ToLower(h.Interval))) {
+		return fmt.Errorf("higherTimeframe.interval: unknown period %q", h.Interval)
+	}
+	if h.EMAFastLength < 2 || h.EMASlowLength < 2 {
+		return fmt.Errorf("higherTimeframe: EMA lengths must be at least 2, got %d and %d", h.EMAFastLength, h.EMASlowLength)
+	}
+	if !h.ConfirmBuy && !h.ConfirmSell {
+		return fmt.Errorf("higherTimeframe: enable confirmBuy or confirmSell")
+	}
+	return nil
+}
+
+func (cs Conditions) Validate(name string) error {
+	if cs.Threshold < 0 {
+		return fmt.Errorf("%s.threshold must not be negative, got %v", name, cs.Threshold)
+	}
+	total := 0.0
+	for _, c := range []struct {
+		name string
+		Condition
+	}{
+		{"emaCross", cs.EMACross},
+		{"rsiCross", cs.RSICross},
+		{"emaTrend", cs.EMATrend},
+		{"cooldown", cs.Cooldown},
+	} {
+		if c.Weight < 0 {
+			return fmt.Errorf("%s.%s.weight must not be negative, got %v", name, c.name, c.Weight)
+		}
+		if c.Enabled {
+			total += c.Weight
+		}
+	}
+	if cs.Threshold > total+1e-9 {
+		return fmt.Errorf("%s.threshold %v exceeds total weight %v of enabled conditions", name, cs.Threshold, total)
+	}
+	return nil
+}
 
-	err = os.WriteFile(primaryPath, data, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+func (r OptimizeRanges) Validate() error {
+	variants := 1
+	for _, ir := range []struct {
+		name string
+		r    IntRange
+	}{
+		{"optimize.rsiLength", r.RSILength},
+		{"optimize.emaSlowLength", r.EMASlowLength},
+		{"optimize.emaFastLength", r.EMAFastLength},
+	} {
+		if ir.r.Min < 2 || ir.r.Max < ir.r.Min || ir.r.Step < 1 {
+			return fmt.Errorf("%s: need 2 <= min <= max and step >= 1, got %+v", ir.name, ir.r)
+		}
+		variants *= (ir.r.Max-ir.r.Min)/ir.r.Step + 1
+	}
+	for _, fr := range []struct {
+		name string
+		r    FloatRange
+	}{
+		{"optimize.rsiBuyLevel", r.RSIBuyLevel},
+		{"optimize.rsiExitLevel", r.RSIExitLevel},
+	} {
+		if fr.r.Min < 0 || fr.r.Max > 100 || fr.r.Max < fr.r.Min || fr.r.Step <= 0 {
+			return fmt.Errorf("%s: need 0 <= min <= max <= 100 and step > 0, got %+v", fr.name, fr.r)
+		}
+		variants *= int((fr.r.Max-fr.r.Min)/fr.r.Step+1e-9) + 1
 	}
+	if variants > maxVariants {
+		return fmt.Errorf("optimize ranges give %d variants, at most %d allowed", variants, maxVariants)
+	}
+	return nil
+}
 
+func checkLevel(name string, v float64) error {
+	if v < 0 || v > 100 {
+		return fmt.Errorf("%s must be between 0 and 100, got %v", name, v)
+	}
 	return nil
 }
+
+// values перебирает значения диапазона от Min до Max включительно.
+func (r IntRange) values() []int {
+	var vals []int
+	for v := r.Min; v <= r.Max; v += r.Step {
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+// values перебирает значения диапазона; шаги целочисленные, чтобы не накапливать ошибку float.
+func (r FloatRange) values() []float64 {
+	var vals []float64
+	for i := 0; ; i++ {
+		v := r.Min + float64(i)*r.Step
+		if v > r.Max+1e-9 {
+			break
+		}
+		vals = append(vals, v)
+	}
+	return vals
+}
@@ -0,0 +1,83 @@
+package indicatorrsi
+
+import (
+	"fmt"
+	"main/internal/resample"
+	"main/internal/strategy"
+	"main/internal/utils"
+	"math"
+
+	"github.com/markcheno/go-quote"
+	"github.com/markcheno/go-talib"
+)
+
+// HigherPeriod возвращает старший период подтверждения, если он включён.
+func (s *RSI) HigherPeriod() (quote.Period, bool) {
+	return utils.ParsePeriod(s.HigherTimeframe.Interval), s.HigherTimeframe.Enabled
+}
+
+// SetHigherQuote задаёт загруженные бары старшего периода на случай,
+// когда их нельзя собрать из баров Execute.
+func (s *RSI) SetHigherQuote(q quote.Quote) {
+	s.higher = q
+}
+
+// higherTrend возвращает быструю и медленную EMA старшего периода, выровненные по барам
+// candles: бару соответствует последний старший бар, закрытый не позже конца этого бара,
+// поэтому незакрытый старший бар в расчёт не попадает. NaN - старших данных ещё нет.
+func (s *RSI) higherTrend(candles quote.Quote) (fast, slow []float64, err error) {
+	cfg := s.HigherTimeframe
+	period := utils.ParsePeriod(cfg.Interval)
+	base := resample.DetectPeriod(candles)
+	if base <= 0 {
+		return nil, nil, fmt.Errorf("cannot detect the data period")
+	}
+	if utils.PeriodDuration(period) <= base {
+		return nil, nil, fmt.Errorf("higher timeframe %s must be longer than the data period %s", period, base)
+	}
+
+	// свежие бары собираем из текущих, чтобы живая сессия видела последние закрытые старшие бары
+	var htf quote.Quote
+	switch {
+	case resample.CanResample(base, period):
+		htf, err = resample.Resample(candles, period, resample.Options{Base: base})
+		if err != nil {
+			return nil, nil, err
+		}
+	case len(s.higher.Date) > 0:
+		htf = s.higher
+	default:
+		return nil, nil, fmt.Errorf("cannot build %s bars from %s bars, reload quotes", period, base)
+	}
+
+	htfFast := emaOrNaN(htf.Close, cfg.EMAFastLength)
+	htfSlow := emaOrNaN(htf.Close, cfg.EMASlowLength)
+
+	fast = make([]float64, len(candles.Date))
+	slow = make([]float64, len(candles.Date))
+	j := -1
+	for i, t := range candles.Date {
+		barEnd := t.Add(base)
+		for j+1 < len(htf.Date) && !resample.BarEnd(htf.Date[j+1], period).After(barEnd) {
+			j++
+		}
+		if j < 0 {
+			fast[i], slow[i] = math.NaN(), math.NaN()
+			continue
+		}
+		fast[i], slow[i] = htfFast[j], htfSlow[j]
+	}
+	return fast, slow, nil
+}
+
+// emaOrNaN считает EMA с NaN на прогреве; при нехватке баров весь ряд NaN.
+func emaOrNaN(values []float64, length int) []float64 {
+	if len(values) < length {
+		out := make([]float64, len(values))
+		for i := range out {
+			out[i] = math.NaN()
+		}
+		return out
+	}
+	return strategy.Warmup(talib.Ema(values, length), length-1)
+}
@@ -3,7 +3,9 @@ package indicatorrsi
 import (
 	"fmt"
 	"main/internal/model"
+	"main/internal/strategy"
 	"math"
+	"time"
 
 	"github.com/markcheno/go-quote"
 	"github.com/markcheno/go-talib"
@@ -15,6 +17,14 @@ type RSI struct {
 	SignalSellPoints []model.IndicatorData
 	RSIValues        []float64
 	EMAValues        []float64
+	EMAFastValues    []float64
+	// HTFFastValues и HTFSlowValues - EMA старшего периода, выровненные по барам
+	HTFFastValues []float64
+	HTFSlowValues []float64
+	// BarExplanations - разбор условий на каждом проанализированном баре, заполняется при verbose
+	BarExplanations []model.Explanation
+
+	higher quote.Quote // загруженные бары старшего периода, см. SetHigherQuote
 }
 
 func NewRSI() (*RSI, error) {
@@ -27,15 +37,18 @@ func NewRSI() (*RSI, error) {
 		Config:           cfg,
 		SignalBuyPoints:  make([]model.IndicatorData, 0),
 		SignalSellPoints: make([]model.IndicatorData, 0),
-		RSIValues:        make([]float64, 0),
-		EMAValues:        make([]float64, 0),
 	}, nil
 }
 
+// Execute пересчитывает сигналы. При verbose для каждого бара сохраняется разбор условий
+// покупки и продажи в BarExplanations, а сигналы получают ссылку на свой разбор.
 func (s *RSI) Execute(candles quote.Quote, verbose bool) (signalBuyOnLast, signalSellOnLast bool) {
 	// --- Очистка сигналов ---
 	s.SignalBuyPoints = s.SignalBuyPoints[:0]
 	s.SignalSellPoints = s.SignalSellPoints[:0]
+	s.RSIValues, s.EMAValues, s.EMAFastValues = nil, nil, nil
+	s.HTFFastValues, s.HTFSlowValues = nil, nil
+	s.BarExplanations = nil
 
 	if len(candles.Close) == 0 {
 		fmt.Println("[RSI] candles.Close = 0")
@@ -47,22 +60,32 @@ func (s *RSI) Execute(candles quote.Quote, verbose bool) (signalBuyOnLast, signa
 	n := len(closes)
 
 	// --- Минимальное количество баров ---
-	minBars := maxInt(s.RSILength, s.EMASlowLength, 20) + 10
-	if n < minBars {
+	minBars := maxInt(s.RSILength, s.EMASlowLength, s.EMAFastLength) + s.WarmupBars
+	if n < minBars || n < 2 {
 		fmt.Println("[RSI] Недостаточно баров для анализа")
 		return false, false
 	}
 
 	// --- Индикаторы ---
-	rsi := talib.Rsi(closes, s.RSILength)
-	emaSlow := talib.Ema(closes, s.EMASlowLength)
-	emaFast := talib.Ema(closes, 20)
+	rsi := strategy.Warmup(talib.Rsi(closes, s.RSILength), s.RSILength)
+	emaSlow := strategy.Warmup(talib.Ema(closes, s.EMASlowLength), s.EMASlowLength-1)
+	emaFast := strategy.Warmup(talib.Ema(closes, s.EMAFastLength), s.EMAFastLength-1)
 
 	// Сохраняем для анализа
 	s.RSIValues = rsi
 	s.EMAValues = emaSlow
+	s.EMAFastValues = emaFast
 
-	startIndex := maxInt(s.RSILength, s.EMASlowLength, 20)
+	htf := s.HigherTimeframe
+	if htf.Enabled {
+		var err error
+		// период и загрузку старших баров проверяет Handler.prepare и возвращает ошибку клиенту
+		if s.HTFFastValues, s.HTFSlowValues, err = s.higherTrend(candles); err != nil {
+			return false, false
+		}
+	}
+
+	startIndex := maxInt(s.RSILength, s.EMASlowLength, s.EMAFastLength)
 	lastBuyIndex := -9999
 	lastSellIndex := -9999
 
@@ -87,7 +110,30 @@ func (s *RSI) Execute(candles quote.Quote, verbose bool) (signalBuyOnLast, signa
 		prevRSI := rsi[i-1]
 		currEMAFast := emaFast[i]
 
-		dateStr := times[i].Format("2006-01-02 15:04")
+		var values map[string]float64
+		if verbose {
+			values = map[string]float64{
+				"close":       currClose,
+				"prevClose":   prevClose,
+				"rsi":         currRSI,
+				"prevRsi":     prevRSI,
+				"emaSlow":     currEMA,
+				"prevEmaSlow": prevEMA,
+				"emaFast":     currEMAFast,
+			}
+		}
+
+		// --- СТАРШИЙ ПЕРИОД ---
+		htfUp, htfDown := false, false
+		if htf.Enabled {
+			htfFast, htfSlow := s.HTFFastValues[i], s.HTFSlowValues[i]
+			htfUp = htfFast > htfSlow // сравнение с NaN ложно: без старших данных нет подтверждения
+			htfDown = htfFast < htfSlow
+			if verbose && !math.IsNaN(htfFast) && !math.IsNaN(htfSlow) {
+				values["htfEmaFast"] = htfFast
+				values["htfEmaSlow"] = htfSlow
+			}
+		}
 
 		// --- BUY CONDITIONS ---
 		buyCond1 := prevClose < prevEMA && currClose > currEMA         // пересечение ценой медленной EMA снизу вверх (трендовый сигнал)
@@ -95,23 +141,38 @@ func (s *RSI) Execute(candles quote.Quote, verbose bool) (signalBuyOnLast, signa
 		buyCond3 := currEMAFast > currEMA                              // быстрая EMA выше медленной
 		buyCond4 := i-lastBuyIndex >= s.MinBarsBetweenTrades           //минимальное расстояние между покупками
 
-		buySignal := buyCond1 && buyCond2 && buyCond3 && buyCond4
+		buyChecks := []check{
+			{"emaCross", s.BuyConditions.EMACross, buyCond1},
+			{"rsiCross", s.BuyConditions.RSICross, buyCond2},
+			{"emaTrend", s.BuyConditions.EMATrend, buyCond3},
+			{"cooldown", s.BuyConditions.Cooldown, buyCond4},
+		}
+		buyScore, buyFired := score(buyChecks...)
+
+		buySignal := len(buyFired) > 0 && buyScore >= s.BuyConditions.Threshold
+		if htf.Enabled && htf.ConfirmBuy {
+			// подтверждение обязательно и не входит в оценку: вес 0
+			buyChecks = append(buyChecks, check{"htfTrend", Condition{Enabled: true}, htfUp})
+			buySignal = buySignal && htfUp
+		}
+
+		var buyExp *model.Explanation
+		if verbose {
+			buyExp = s.explain(times[i], "buy", buySignal, buyChecks, s.BuyConditions.Threshold, values)
+		}
 
 		if buySignal {
 			lastBuyIndex = i
 			s.SignalBuyPoints = append(s.SignalBuyPoints, model.IndicatorData{
-				Date:  times[i],
-				Value: currClose,
+				Date:        times[i],
+				Value:       currClose,
+				Conditions:  buyFired,
+				Score:       buyScore,
+				Explanation: buyExp,
 			})
 			if i == n-1 {
 				signalBuyOnLast = true
 			}
-
-			if verbose {
-				fmt.Printf("[BUY] %s | Close=%.2f | RSI=%.1f | EMA=%.2f | FastEMA=%.2f\n", dateStr, currClose, currRSI, currEMA, currEMAFast)
-				fmt.Printf("      cond1(cross up EMA)=%v cond2(RSI zone)=%v cond3(Fast>Slow)=%v cond4(cooldown)=%v\n",
-					buyCond1, buyCond2, buyCond3, buyCond4)
-			}
 			continue
 		}
 
@@ -121,44 +182,101 @@ func (s *RSI) Execute(candles quote.Quote, verbose bool) (signalBuyOnLast, signa
 		sellCond3 := i-lastSellIndex >= s.MinBarsBetweenTrades            //защита от слишком частых сигналов
 		sellCond4 := currEMAFast < currEMA                                // быстрая EMA ниже медленной
 
-		trueCount := 0
-		if sellCond1 {
-			trueCount++
-		}
-		if sellCond2 {
-			trueCount++
-		}
-		if sellCond3 {
-			trueCount++
+		sellChecks := []check{
+			{"emaCross", s.SellConditions.EMACross, sellCond1},
+			{"rsiCross", s.SellConditions.RSICross, sellCond2},
+			{"cooldown", s.SellConditions.Cooldown, sellCond3},
+			{"emaTrend", s.SellConditions.EMATrend, sellCond4},
 		}
-		if sellCond4 {
-			trueCount++
+		sellScore, sellFired := score(sellChecks...)
+
+		sellSignal := len(sellFired) > 0 && sellScore >= s.SellConditions.Threshold
+		if htf.Enabled && htf.ConfirmSell {
+			sellChecks = append(sellChecks, check{"htfTrend", Condition{Enabled: true}, htfDown})
+			sellSignal = sellSignal && htfDown
 		}
 
-		sellSignal := trueCount >= s.CountSellSignals
+		var sellExp *model.Explanation
+		if verbose {
+			sellExp = s.explain(times[i], "sell", sellSignal, sellChecks, s.SellConditions.Threshold, values)
+		}
 
 		if sellSignal {
 			lastSellIndex = i
 			s.SignalSellPoints = append(s.SignalSellPoints, model.IndicatorData{
-				Date:  times[i],
-				Value: currClose,
+				Date:        times[i],
+				Value:       currClose,
+				Conditions:  sellFired,
+				Score:       sellScore,
+				Explanation: sellExp,
 			})
 			if i == n-1 {
 				signalSellOnLast = true
 			}
-
-			if verbose {
-				fmt.Printf("[SELL] %s | Close=%.2f | RSI=%.1f | EMA=%.2f | FastEMA=%.2f\n", dateStr, currClose, currRSI, currEMA, currEMAFast)
-				fmt.Printf("       cond1(cross down EMA)=%v cond2(RSI down)=%v cond3(cooldown)=%v\n",
-					sellCond1, sellCond2, sellCond3)
-			}
 		}
 	}
 
 	return signalBuyOnLast, signalSellOnLast
 }
 
+// explain добавляет в BarExplanations разбор условий стороны side на баре date и возвращает его.
+// Бар считается почти сработавшим, если для сигнала не хватило одного включённого условия
+// (или только подтверждения старшего периода), а одно из пересечений (цены с EMA или RSI с уровнем) произошло: состояния вроде
+// "быстрая EMA выше медленной" держатся много баров подряд и сами по себе не интересны.
+func (s *RSI) explain(date time.Time, side string, signal bool, checks []check, threshold float64, values map[string]float64) *model.Explanation {
+	total, _ := score(checks...)
+	missing := 0.0
+	crossed := false
+	results := make([]model.ConditionResult, len(checks))
+	for i, c := range checks {
+		results[i] = model.ConditionResult{Name: c.name, Enabled: c.Enabled, Weight: c.Weight, Passed: c.ok}
+		if c.Enabled && !c.ok {
+			missing = math.Max(missing, c.Weight)
+		}
+		if c.Enabled && c.ok && (c.name == "emaCross" || c.name == "rsiCross") {
+			crossed = true
+		}
+	}
+	nearMiss := !signal && crossed && (total >= threshold || (missing > 0 && total+missing >= threshold))
+
+	levels := s.Levels()
+	levels["minBarsBetweenTrades"] = float64(s.MinBarsBetweenTrades)
+
+	s.BarExplanations = append(s.BarExplanations, model.Explanation{
+		Date:       date,
+		Side:       side,
+		Signal:     signal,
+		NearMiss:   nearMiss,
+		Score:      total,
+		Threshold:  threshold,
+		Values:     values,
+		Levels:     levels,
+		Conditions: results,
+	})
+	exp := s.BarExplanations[len(s.BarExplanations)-1]
+	return &exp
+}
+
 // --- helpers ---
+
+// check - результат условия вместе с его настройкой.
+type check struct {
+	name string
+	Condition
+	ok bool
+}
+
+// score суммирует веса выполненных включённых условий и возвращает их имена.
+func score(checks ...check) (total float64, fired []string) {
+	for _, c := range checks {
+		if c.Enabled && c.ok {
+			total += c.Weight
+			fired = append(fired, c.name)
+		}
+	}
+	return total, fired
+}
+
 func maxInt(vals ...int) int {
 	if len(vals) == 0 {
 		return 0
@@ -0,0 +1,80 @@
+package indicatorrsi
+
+import (
+	"main/internal/model"
+	"main/internal/strategy"
+)
+
+func init() {
+	strategy.Register(strategy.Definition{
+		Name: "rsi",
+		New: func() (strategy.Strategy, error) {
+			return NewRSI()
+		},
+		Store: Store,
+	})
+}
+
+func (s *RSI) Settings() any {
+	return s.Config
+}
+
+func (s *RSI) BuySignals() []model.IndicatorData {
+	return s.SignalBuyPoints
+}
+
+func (s *RSI) SellSignals() []model.IndicatorData {
+	return s.SignalSellPoints
+}
+
+func (s *RSI) Series() map[string]model.Series {
+	series := map[string]model.Series{
+		"rsi":     s.RSIValues,
+		"emaSlow": s.EMAValues,
+		"emaFast": s.EMAFastValues,
+	}
+	if s.HigherTimeframe.Enabled {
+		series["htfEmaFast"] = s.HTFFastValues
+		series["htfEmaSlow"] = s.HTFSlowValues
+	}
+	return series
+}
+
+// Levels - уровни пересечения RSI для входа и выхода.
+func (s *RSI) Levels() map[string]float64 {
+	return map[string]float64{
+		"rsiBuyLevel":  s.RSIBuyLevel,
+		"rsiExitLevel": s.RSIExitLevel,
+	}
+}
+
+func (s *RSI) Explanations() []model.Explanation {
+	return s.BarExplanations
+}
+
+// Variants перебирает длины RSI, медленной и быстрой EMA и уровни входа/выхода
+// в диапазонах Config.Optimize, остальные параметры берутся из текущей конфигурации.
+func (s *RSI) Variants(yield func(strategy.Strategy) bool) {
+	r := s.Optimize
+	for _, rsiLen := range r.RSILength.values() {
+		for _, emaSlow := range r.EMASlowLength.values() {
+			for _, emaFast := range r.EMAFastLength.values() {
+				for _, buyLevel := range r.RSIBuyLevel.values() {
+					for _, exitLevel := range r.RSIExitLevel.values() {
+						// копия Config, чтобы варианты не делили одну конфигурацию
+						cfg := *s.Config
+						cfg.RSILength = rsiLen
+						cfg.EMASlowLength = emaSlow
+						cfg.EMAFastLength = emaFast
+						cfg.RSIBuyLevel = buyLevel
+						cfg.RSIExitLevel = exitLevel
+
+						if !yield(&RSI{Config: &cfg, higher: s.higher}) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package indicatorrules
+
+import (
+	"fmt"
+	"main/internal/rule"
+	"main/internal/strategy"
+)
+
+// Store хранит правила: config.yaml, а при его отсутствии config.default.yaml.
+var Store = strategy.ConfigStore{
+	Primary:  "internal/indicator/rules/config.yaml",
+	Fallback: "internal/indicator/rules/config.default.yaml",
+}
+
+type Config struct {
+	Buy  string `yaml:"buy" json:"buy"`   // условие покупки
+	Sell string `yaml:"sell" json:"sell"` // условие продажи
+
+	OnChange bool `yaml:"on_change" json:"onChange"` // сигнал только при переходе правила из false в true
+}
+
+func NewConfig() (*Config, error) {
+	config := Config{OnChange: true}
+	if err := Store.Load(&config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules config: %w", err)
+	}
+	return &config, nil
+}
+
+func (c *Config) SaveConfig() error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	return Store.Save(c)
+}
+
+// Validate разбирает оба правила; ошибка содержит имя правила и позицию в нём.
+func (c *Config) Validate() error {
+	_, _, err := c.compile()
+	return err
+}
+
+func (c *Config) compile() (buy, sell *rule.Rule, err error) {
+	if buy, err = rule.Compile(c.Buy); err != nil {
+		return nil, nil, fmt.Errorf("buy: %w", err)
+	}
+	if sell, err = rule.Compile(c.Sell); err != nil {
+		return nil, nil, fmt.Errorf("sell: %w", err)
+	}
+	return buy, sell, nil
+}
@@ -0,0 +1,75 @@
+package indicatorrules
+
+import (
+	"fmt"
+	"main/internal/model"
+	"main/internal/rule"
+
+	"github.com/markcheno/go-quote"
+)
+
+// Rules - стратегия, сигналы которой задаются выражениями Config.Buy и Config.Sell.
+type Rules struct {
+	*Config
+	SignalBuyPoints  []model.IndicatorData
+	SignalSellPoints []model.IndicatorData
+	Indicators       map[string][]float64
+}
+
+func NewRules() (*Rules, error) {
+	cfg, err := NewConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rules{
+		Config:           cfg,
+		SignalBuyPoints:  make([]model.IndicatorData, 0),
+		SignalSellPoints: make([]model.IndicatorData, 0),
+	}, nil
+}
+
+func (s *Rules) Execute(candles quote.Quote, verbose bool) (signalBuyOnLast, signalSellOnLast bool) {
+	s.SignalBuyPoints = s.SignalBuyPoints[:0]
+	s.SignalSellPoints = s.SignalSellPoints[:0]
+	s.Indicators = nil
+
+	if len(candles.Close) == 0 {
+		fmt.Println("[RULES] candles.Close = 0")
+		return false, false
+	}
+	buyRule, sellRule, err := s.compile()
+	if err != nil {
+		fmt.Println("[RULES]", err)
+		return false, false
+	}
+
+	e := rule.NewEvaluator(candles)
+	buy := e.Eval(buyRule)
+	sell := e.Eval(sellRule)
+	s.Indicators = e.Indicators()
+
+	n := len(candles.Close)
+	for i := 0; i < n; i++ {
+		// на одном баре покупка имеет приоритет, как в RSI
+		if s.fires(buy, i) {
+			s.SignalBuyPoints = append(s.SignalBuyPoints, model.IndicatorData{Date: candles.Date[i], Value: candles.Close[i]})
+			signalBuyOnLast = i == n-1
+			continue
+		}
+		if s.fires(sell, i) {
+			s.SignalSellPoints = append(s.SignalSellPoints, model.IndicatorData{Date: candles.Date[i], Value: candles.Close[i]})
+			signalSellOnLast = i == n-1
+		}
+	}
+
+	return signalBuyOnLast, signalSellOnLast
+}
+
+// fires сообщает, даёт ли правило сигнал на баре i с учётом OnChange.
+func (s *Rules) fires(values []bool, i int) bool {
+	if !values[i] {
+		return false
+	}
+	return !s.OnChange || i == 0 || !values[i-1]
+}
@@ -0,0 +1,47 @@
+package indicatorrules
+
+import (
+	"main/internal/model"
+	"main/internal/strategy"
+)
+
+func init() {
+	strategy.Register(strategy.Definition{
+		Name: "rules",
+		New: func() (strategy.Strategy, error) {
+			return NewRules()
+		},
+		Store: Store,
+	})
+}
+
+func (s *Rules) Settings() any {
+	return s.Config
+}
+
+func (s *Rules) BuySignals() []model.IndicatorData {
+	return s.SignalBuyPoints
+}
+
+func (s *Rules) SellSignals() []model.IndicatorData {
+	return s.SignalSellPoints
+}
+
+// Series возвращает индикаторы из правил под их записью, например "ema(close, 51)".
+func (s *Rules) Series() map[string]model.Series {
+	series := make(map[string]model.Series, len(s.Indicators))
+	for name, values := range s.Indicators {
+		series[name] = values
+	}
+	return series
+}
+
+func (s *Rules) Levels() map[string]float64 {
+	return map[string]float64{}
+}
+
+// Variants - у правил нет параметров для перебора, оптимизация оценивает текущие правила.
+func (s *Rules) Variants(yield func(strategy.Strategy) bool) {
+	cfg := *s.Config
+	yield(&Rules{Config: &cfg})
+}
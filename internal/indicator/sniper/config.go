@@ -0,0 +1,42 @@
+package indicatorsniper
+
+import "main/internal/strategy"
+
+// Store хранит конфигурацию Sniper: config.yaml, а при его отсутствии config.default.yaml.
+var Store = strategy.ConfigStore{
+	Primary:  "internal/indicator/sniper/config.yaml",
+	Fallback: "internal/indicator/sniper/config.default.yaml",
+}
+
+// Config совпадает с SniperConfig во frontend/src/types/types.d.ts.
+type Config struct {
+	// Индикаторы
+	RSILength       int `yaml:"rsi_length" json:"RSILength"`              // длина RSI и MFI
+	EMAFastLength   int `yaml:"ema_fast_length" json:"EMAFastLength"`     // длина быстрой EMA
+	EMASlowLength   int `yaml:"ema_slow_length" json:"EMASlowLength"`     // длина медленной EMA
+	ATRLength       int `yaml:"atr_length" json:"ATRLength"`              // длина ATR
+	ATRSMALength    int `yaml:"atr_sma_length" json:"ATRSMALength"`       // сглаживание ATR
+	VolumeSMALength int `yaml:"volume_sma_length" json:"VolumeSMALength"` // средний объём
+
+	// Уровни среднего RSI и MFI
+	RSIMFIBuyLevel  float64 `yaml:"rsi_mfi_buy_level" json:"RSIMFIBuyLevel"`   // пересечение снизу вверх - вход
+	RSIMFIExitLevel float64 `yaml:"rsi_mfi_exit_level" json:"RSIMFIExitLevel"` // пересечение сверху вниз - выход
+
+	// Фильтры
+	EMAMinDelta      float64 `yaml:"ema_min_delta" json:"EMAMinDelta"`           // минимальный отрыв быстрой EMA от медленной, доля цены
+	ATRMultiplier    float64 `yaml:"atr_multiplier" json:"ATRMultiplier"`        // ATR должен быть выше ATRMultiplier * SMA(ATR)
+	BuyVolumeFactor  float64 `yaml:"buy_volume_factor" json:"BuyVolumeFactor"`   // объём при входе выше BuyVolumeFactor * SMA(объёма)
+	SellVolumeFactor float64 `yaml:"sell_volume_factor" json:"SellVolumeFactor"` // объём при выходе выше SellVolumeFactor * SMA(объёма)
+}
+
+func NewConfig() (*Config, error) {
+	var config Config
+	if err := Store.Load(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *Config) SaveConfig() error {
+	return Store.Save(c)
+}
@@ -0,0 +1,251 @@
+package indicatorsniper
+
+import (
+	"fmt"
+	"main/internal/model"
+	"main/internal/strategy"
+	"math"
+	"time"
+
+	"github.com/markcheno/go-quote"
+	"github.com/markcheno/go-talib"
+)
+
+// Sniper входит, когда среднее RSI и MFI выходит из зоны перепроданности
+// при растущем тренде (быстрая EMA выше медленной), достаточной волатильности
+// и повышенном объёме. Выход - по развороту осциллятора или пересечению EMA вниз.
+type Sniper struct {
+	*Config
+	SignalBuyPoints  []model.IndicatorData
+	SignalSellPoints []model.IndicatorData
+	RSIMFIValues     []float64
+	EMAFastValues    []float64
+	EMASlowValues    []float64
+	ATRValues        []float64
+	ATRSMAValues     []float64
+	VolumeSMAValues  []float64
+	// BarExplanations - разбор условий на каждом проанализированном баре, заполняется при verbose
+	BarExplanations []model.Explanation
+}
+
+func NewSniper() (*Sniper, error) {
+	cfg, err := NewConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sniper{
+		Config:           cfg,
+		SignalBuyPoints:  make([]model.IndicatorData, 0),
+		SignalSellPoints: make([]model.IndicatorData, 0),
+	}, nil
+}
+
+// Execute пересчитывает сигналы. При verbose для каждого бара сохраняется разбор условий
+// стороны, которую стратегия проверяет на этом баре: покупки вне позиции, продажи в позиции.
+func (s *Sniper) Execute(candles quote.Quote, verbose bool) (signalBuyOnLast, signalSellOnLast bool) {
+	// --- Очистка сигналов ---
+	s.SignalBuyPoints = s.SignalBuyPoints[:0]
+	s.SignalSellPoints = s.SignalSellPoints[:0]
+	s.RSIMFIValues, s.EMAFastValues, s.EMASlowValues = nil, nil, nil
+	s.ATRValues, s.ATRSMAValues, s.VolumeSMAValues = nil, nil, nil
+	s.BarExplanations = nil
+
+	if len(candles.Close) == 0 {
+		fmt.Println("[SNIPER] candles.Close = 0")
+		return false, false
+	}
+	if s.RSILength < 2 || s.EMAFastLength < 2 || s.EMASlowLength < 2 ||
+		s.ATRLength < 1 || s.ATRSMALength < 2 || s.VolumeSMALength < 2 {
+		fmt.Println("[SNIPER] Некорректные длины индикаторов")
+		return false, false
+	}
+
+	closes := candles.Close
+	volumes := candles.Volume
+	times := candles.Date
+	n := len(closes)
+
+	// --- Минимальное количество баров ---
+	startIndex := maxInt(s.RSILength, s.EMAFastLength, s.EMASlowLength, s.ATRLength+s.ATRSMALength, s.VolumeSMALength)
+	if n < startIndex+10 {
+		fmt.Println("[SNIPER] Недостаточно баров для анализа")
+		return false, false
+	}
+
+	// --- Индикаторы ---
+	rsi := talib.Rsi(closes, s.RSILength)
+	mfi := talib.Mfi(candles.High, candles.Low, closes, volumes, s.RSILength)
+	rsiMfi := make([]float64, n)
+	for i := range rsiMfi {
+		rsiMfi[i] = (rsi[i] + mfi[i]) / 2
+	}
+	strategy.Warmup(rsiMfi, s.RSILength)
+	emaFast := strategy.Warmup(talib.Ema(closes, s.EMAFastLength), s.EMAFastLength-1)
+	emaSlow := strategy.Warmup(talib.Ema(closes, s.EMASlowLength), s.EMASlowLength-1)
+	atr := talib.Atr(candles.High, candles.Low, closes, s.ATRLength)
+	// SMA считается по ATR до маскирования прогрева, иначе NaN попадёт во всё окно
+	atrSma := strategy.Warmup(talib.Sma(atr, s.ATRSMALength), s.ATRLength+s.ATRSMALength-1)
+	strategy.Warmup(atr, s.ATRLength)
+	volumeSma := strategy.Warmup(talib.Sma(volumes, s.VolumeSMALength), s.VolumeSMALength-1)
+
+	// Сохраняем для анализа
+	s.RSIMFIValues = rsiMfi
+	s.EMAFastValues = emaFast
+	s.EMASlowValues = emaSlow
+	s.ATRValues = atr
+	s.ATRSMAValues = atrSma
+	s.VolumeSMAValues = volumeSma
+
+	inPosition := false
+
+	for i := startIndex; i < n; i++ {
+		if !finite(rsiMfi[i], rsiMfi[i-1], emaFast[i], emaFast[i-1], emaSlow[i], emaSlow[i-1], atr[i], atrSma[i], volumeSma[i]) {
+			continue
+		}
+
+		currClose := closes[i]
+		currRSIMFI := rsiMfi[i]
+		prevRSIMFI := rsiMfi[i-1]
+		currFast, prevFast := emaFast[i], emaFast[i-1]
+		currSlow, prevSlow := emaSlow[i], emaSlow[i-1]
+
+		var values map[string]float64
+		if verbose {
+			values = map[string]float64{
+				"close":      currClose,
+				"rsiMfi":     currRSIMFI,
+				"prevRsiMfi": prevRSIMFI,
+				"emaFast":    currFast,
+				"emaSlow":    currSlow,
+				"atr":        atr[i],
+				"atrSma":     atrSma[i],
+				"volume":     volumes[i],
+				"volumeSma":  volumeSma[i],
+			}
+		}
+
+		if !inPosition {
+			// --- BUY CONDITIONS ---
+			buyCond1 := prevRSIMFI < s.RSIMFIBuyLevel && currRSIMFI >= s.RSIMFIBuyLevel // RSI/MFI выходит из перепроданности
+			buyCond2 := currFast-currSlow > s.EMAMinDelta*currClose                     // быстрая EMA выше медленной с отрывом
+			buyCond3 := atr[i] > s.ATRMultiplier*atrSma[i]                              // рынок достаточно волатилен
+			buyCond4 := volumes[i] > s.BuyVolumeFactor*volumeSma[i]                     // вход подтверждён объёмом
+
+			buySignal := buyCond1 && buyCond2 && buyCond3 && buyCond4
+
+			var buyExp *model.Explanation
+			if verbose {
+				// все условия обязательны: вес 1 и порог по их числу
+				buyExp = s.explain(times[i], "buy", buySignal, buyCond1, 4, values, []model.ConditionResult{
+					{Name: "rsiMfiCross", Enabled: true, Weight: 1, Passed: buyCond1},
+					{Name: "emaTrend", Enabled: true, Weight: 1, Passed: buyCond2},
+					{Name: "volatility", Enabled: true, Weight: 1, Passed: buyCond3},
+					{Name: "volume", Enabled: true, Weight: 1, Passed: buyCond4},
+				})
+			}
+
+			if buySignal {
+				inPosition = true
+				s.SignalBuyPoints = append(s.SignalBuyPoints, model.IndicatorData{
+					Date:        times[i],
+					Value:       currClose,
+					Explanation: buyExp,
+				})
+				if i == n-1 {
+					signalBuyOnLast = true
+				}
+			}
+			continue
+		}
+
+		// --- SELL CONDITIONS ---
+		sellCond1 := prevRSIMFI > s.RSIMFIExitLevel && currRSIMFI <= s.RSIMFIExitLevel // импульс ослаб
+		sellCond2 := prevFast >= prevSlow && currFast < currSlow                       // быстрая EMA пересекла медленную вниз
+		sellCond3 := volumes[i] > s.SellVolumeFactor*volumeSma[i]                      // выход не на пустом рынке
+
+		sellSignal := (sellCond1 || sellCond2) && sellCond3
+
+		var sellExp *model.Explanation
+		if verbose {
+			// достаточно одного из разворотов (вес 1) вместе с объёмом (вес 2)
+			sellExp = s.explain(times[i], "sell", sellSignal, sellCond1 || sellCond2, 3, values, []model.ConditionResult{
+				{Name: "rsiMfiCross", Enabled: true, Weight: 1, Passed: sellCond1},
+				{Name: "emaCross", Enabled: true, Weight: 1, Passed: sellCond2},
+				{Name: "volume", Enabled: true, Weight: 2, Passed: sellCond3},
+			})
+		}
+
+		if sellSignal {
+			inPosition = false
+			s.SignalSellPoints = append(s.SignalSellPoints, model.IndicatorData{
+				Date:        times[i],
+				Value:       currClose,
+				Explanation: sellExp,
+			})
+			if i == n-1 {
+				signalSellOnLast = true
+			}
+		}
+	}
+
+	return signalBuyOnLast, signalSellOnLast
+}
+
+// explain добавляет в BarExplanations разбор условий стороны side на баре date и возвращает его.
+// Бар считается почти сработавшим, если произошёл разворот осциллятора или EMA (crossed),
+// а для сигнала не хватило одного условия.
+func (s *Sniper) explain(date time.Time, side string, signal, crossed bool, threshold float64, values map[string]float64, conditions []model.ConditionResult) *model.Explanation {
+	total, missing := 0.0, 0.0
+	for _, c := range conditions {
+		if c.Passed {
+			total += c.Weight
+		} else {
+			missing = math.Max(missing, c.Weight)
+		}
+	}
+	nearMiss := !signal && crossed && total+missing >= threshold
+
+	levels := s.Levels()
+	levels["emaMinDelta"] = s.EMAMinDelta
+	levels["atrMultiplier"] = s.ATRMultiplier
+	levels["buyVolumeFactor"] = s.BuyVolumeFactor
+	levels["sellVolumeFactor"] = s.SellVolumeFactor
+
+	s.BarExplanations = append(s.BarExplanations, model.Explanation{
+		Date:       date,
+		Side:       side,
+		Signal:     signal,
+		NearMiss:   nearMiss,
+		Score:      total,
+		Threshold:  threshold,
+		Values:     values,
+		Levels:     levels,
+		Conditions: conditions,
+	})
+	exp := s.BarExplanations[len(s.BarExplanations)-1]
+	return &exp
+}
+
+// --- helpers ---
+func maxInt(vals ...int) int {
+	if len(vals) == 0 {
+		return 0
+	}
+	m := vals[0]
+	for _, v := range vals[1:] {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+func finite(vals ...float64) bool {
+	for _, v := range vals {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
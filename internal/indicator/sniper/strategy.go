@@ -0,0 +1,89 @@
+package indicatorsniper
+
+import (
+	"main/internal/model"
+	"main/internal/strategy"
+)
+
+func init() {
+	strategy.Register(strategy.Definition{
+		Name: "sniper",
+		New: func() (strategy.Strategy, error) {
+			return NewSniper()
+		},
+		Store: Store,
+	})
+}
+
+func (s *Sniper) Settings() any {
+	return s.Config
+}
+
+func (s *Sniper) BuySignals() []model.IndicatorData {
+	return s.SignalBuyPoints
+}
+
+func (s *Sniper) SellSignals() []model.IndicatorData {
+	return s.SignalSellPoints
+}
+
+func (s *Sniper) Series() map[string]model.Series {
+	return map[string]model.Series{
+		"rsiMfi":    s.RSIMFIValues,
+		"emaFast":   s.EMAFastValues,
+		"emaSlow":   s.EMASlowValues,
+		"atr":       s.ATRValues,
+		"atrSma":    s.ATRSMAValues,
+		"volumeSma": s.VolumeSMAValues,
+	}
+}
+
+func (s *Sniper) Explanations() []model.Explanation {
+	return s.BarExplanations
+}
+
+// Levels - уровни пересечения среднего RSI и MFI для входа и выхода.
+func (s *Sniper) Levels() map[string]float64 {
+	return map[string]float64{
+		"rsiMfiBuyLevel":  s.RSIMFIBuyLevel,
+		"rsiMfiExitLevel": s.RSIMFIExitLevel,
+	}
+}
+
+// Variants перебирает длины EMA и уровни RSI/MFI, остальные параметры
+// берутся из текущей конфигурации.
+func (s *Sniper) Variants(yield func(strategy.Strategy) bool) {
+	// Диапазоны параметров
+	fastMin, fastMax := 5, 13
+	slowMin, slowMax := 30, 100
+
+	buyMin, buyMax, buyStep := 30.0, 45.0, 3.0
+	exitMin, exitMax, exitStep := 45.0, 65.0, 5.0
+
+	for fast := fastMin; fast <= fastMax; fast += 2 {
+		for slow := slowMin; slow <= slowMax; slow += 10 {
+			for ib := 0; ; ib++ {
+				buyLevel := buyMin + float64(ib)*buyStep
+				if buyLevel > buyMax+1e-9 {
+					break
+				}
+				for ie := 0; ; ie++ {
+					exitLevel := exitMin + float64(ie)*exitStep
+					if exitLevel > exitMax+1e-9 {
+						break
+					}
+
+					cfg := *s.Config
+					cfg.EMAFastLength = fast
+					cfg.EMASlowLength = slow
+					cfg.RSIMFIBuyLevel = buyLevel
+					cfg.RSIMFIExitLevel = exitLevel
+
+					if !yield(&Sniper{Config: &cfg}) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
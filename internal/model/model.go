@@ -1,8 +1,61 @@
 package model
 
-import "time"
+import (
+	"math"
+	"strconv"
+	"time"
+)
 
 type IndicatorData struct {
 	Date  time.Time `json:"date"`
 	Value float64   `json:"value"`
+
+	// Conditions - сработавшие условия сигнала и их суммарный вес, если стратегия их сообщает
+	Conditions []string `json:"conditions,omitempty"`
+	Score      float64  `json:"score,omitempty"`
+
+	Explanation *Explanation `json:"explanation,omitempty"`
+}
+
+// Explanation - разбор условий стратегии на одном баре.
+type Explanation struct {
+	Date       time.Time          `json:"date"`
+	Side       string             `json:"side"`     // buy или sell
+	Signal     bool               `json:"signal"`   // сигнал возник
+	NearMiss   bool               `json:"nearMiss"` // для сигнала не хватило одного условия
+	Score      float64            `json:"score"`
+	Threshold  float64            `json:"threshold"`
+	Values     map[string]float64 `json:"values"` // цена и индикаторы на баре
+	Levels     map[string]float64 `json:"levels"` // пороговые уровни
+	Conditions []ConditionResult  `json:"conditions"`
+}
+
+type ConditionResult struct {
+	Name    string  `json:"name"`
+	Enabled bool    `json:"enabled"`
+	Weight  float64 `json:"weight"`
+	Passed  bool    `json:"passed"`
+}
+
+// Series - значения индикатора, выровненные по барам котировки.
+// NaN и Inf (прогрев индикатора, деление на ноль) кодируются в JSON как null.
+type Series []float64
+
+func (s Series) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+	buf := make([]byte, 0, len(s)*8+2)
+	buf = append(buf, '[')
+	for i, v := range s {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			buf = append(buf, "null"...)
+			continue
+		}
+		buf = strconv.AppendFloat(buf, v, 'f', -1, 64)
+	}
+	return append(buf, ']'), nil
 }
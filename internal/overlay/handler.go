@@ -0,0 +1,67 @@
+package overlay
+
+import (
+	"main/internal/app"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler отдаёт реестр индикаторов и считает их по текущей котировке App.
+type Handler struct {
+	app *app.App
+}
+
+func NewHandler(app *app.App) *Handler {
+	return &Handler{app: app}
+}
+
+func (h *Handler) Register(router gin.IRouter) {
+	router.GET("indicators", h.GetIndicators)
+	router.POST("indicators/overlay", h.Overlay)
+}
+
+func (h *Handler) GetIndicators(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"indicators": Indicators(),
+	})
+}
+
+// Overlay считает индикаторы из тела запроса по котировке App.Symbol/App.Interval:
+// {"indicators": [{"name": "macd", "params": {"fast": 12}}, {"name": "bbands"}]}.
+func (h *Handler) Overlay(c *gin.Context) {
+	var req struct {
+		Indicators []Request `json:"indicators"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Indicators) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no indicators requested"})
+		return
+	}
+
+	sel, q, ok := h.app.Current()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no quote data for symbol/interval"})
+		return
+	}
+
+	overlays := make([]Overlay, 0, len(req.Indicators))
+	for _, r := range req.Indicators {
+		overlay, err := Compute(q, r)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		overlays = append(overlays, overlay)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"symbol":   sel.Symbol,
+		"interval": sel.Interval,
+		"dates":    q.Date,
+		"overlays": overlays,
+	})
+}
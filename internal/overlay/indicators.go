@@ -0,0 +1,125 @@
+package overlay
+
+import (
+	"github.com/markcheno/go-quote"
+	"github.com/markcheno/go-talib"
+)
+
+func init() {
+	// средние и полосы поверх цены
+	register(closeIndicator("sma", "Simple moving average", PanePrice, minus1, talib.Sma))
+	register(closeIndicator("ema", "Exponential moving average", PanePrice, minus1, talib.Ema))
+	register(closeIndicator("wma", "Weighted moving average", PanePrice, minus1, talib.Wma))
+	register(Indicator{
+		Name: "bbands", Title: "Bollinger Bands", Pane: PanePrice,
+		Params:   []Param{length("period", 20), {Name: "devUp", Default: 2}, {Name: "devDown", Default: 2}},
+		Outputs:  []string{"upper", "middle", "lower"},
+		lookback: func(p []float64) int { return int(p[0]) - 1 },
+		compute: func(q quote.Quote, p []float64) [][]float64 {
+			upper, middle, lower := talib.BBands(q.Close, int(p[0]), p[1], p[2], talib.SMA)
+			return [][]float64{upper, middle, lower}
+		},
+	})
+	register(Indicator{
+		Name: "sar", Title: "Parabolic SAR", Pane: PanePrice,
+		Params:   []Param{{Name: "acceleration", Default: 0.02}, {Name: "maximum", Default: 0.2}},
+		Outputs:  []string{"sar"},
+		lookback: func([]float64) int { return 1 },
+		compute: func(q quote.Quote, p []float64) [][]float64 {
+			return [][]float64{talib.Sar(q.High, q.Low, p[0], p[1])}
+		},
+	})
+
+	// осцилляторы
+	register(closeIndicator("rsi", "Relative strength index", PaneOscillator, same, talib.Rsi))
+	register(closeIndicator("mom", "Momentum", PaneOscillator, same, talib.Mom))
+	register(closeIndicator("roc", "Rate of change", PaneOscillator, same, talib.Roc))
+	register(Indicator{
+		Name: "stddev", Title: "Standard deviation", Pane: PaneOscillator,
+		Params:   []Param{length("period", 20), {Name: "devs", Default: 1}},
+		Outputs:  []string{"stddev"},
+		lookback: func(p []float64) int { return int(p[0]) - 1 },
+		compute: func(q quote.Quote, p []float64) [][]float64 {
+			return [][]float64{talib.StdDev(q.Close, int(p[0]), p[1])}
+		},
+	})
+	register(Indicator{
+		Name: "macd", Title: "MACD", Pane: PaneOscillator,
+		Params:   []Param{length("fast", 12), length("slow", 26), {Name: "signal", Default: 9, Min: 1, Integer: true}},
+		Outputs:  []string{"macd", "signal", "hist"},
+		lookback: func(p []float64) int { return int(max(p[0], p[1])) - 1 + int(p[2]) - 1 },
+		compute: func(q quote.Quote, p []float64) [][]float64 {
+			macd, signal, hist := talib.Macd(q.Close, int(p[0]), int(p[1]), int(p[2]))
+			return [][]float64{macd, signal, hist}
+		},
+	})
+	register(Indicator{
+		Name: "stoch", Title: "Stochastic", Pane: PaneOscillator,
+		Params: []Param{
+			{Name: "fastK", Default: 14, Min: 1, Integer: true},
+			{Name: "slowK", Default: 3, Min: 1, Integer: true},
+			{Name: "slowD", Default: 3, Min: 1, Integer: true},
+		},
+		Outputs:  []string{"k", "d"},
+		lookback: func(p []float64) int { return int(p[0]) - 1 + int(p[1]) - 1 + int(p[2]) - 1 },
+		compute: func(q quote.Quote, p []float64) [][]float64 {
+			k, d := talib.Stoch(q.High, q.Low, q.Close, int(p[0]), int(p[1]), talib.SMA, int(p[2]), talib.SMA)
+			return [][]float64{k, d}
+		},
+	})
+	register(hlcIndicator("atr", "Average true range", same, talib.Atr))
+	register(hlcIndicator("adx", "Average directional index", func(n int) int { return 2*n - 1 }, talib.Adx))
+	register(hlcIndicator("cci", "Commodity channel index", minus1, talib.Cci))
+	register(hlcIndicator("willr", "Williams %R", minus1, talib.WillR))
+	register(Indicator{
+		Name: "mfi", Title: "Money flow index", Pane: PaneOscillator,
+		Params:   []Param{length("period", 14)},
+		Outputs:  []string{"mfi"},
+		lookback: func(p []float64) int { return int(p[0]) },
+		compute: func(q quote.Quote, p []float64) [][]float64 {
+			return [][]float64{talib.Mfi(q.High, q.Low, q.Close, q.Volume, int(p[0]))}
+		},
+	})
+	register(Indicator{
+		Name: "obv", Title: "On-balance volume", Pane: PaneOscillator,
+		Params:   []Param{},
+		Outputs:  []string{"obv"},
+		lookback: func([]float64) int { return 0 },
+		compute: func(q quote.Quote, _ []float64) [][]float64 {
+			return [][]float64{talib.Obv(q.Close, q.Volume)}
+		},
+	})
+}
+
+func same(n int) int   { return n }
+func minus1(n int) int { return n - 1 }
+
+func length(name string, def float64) Param {
+	return Param{Name: name, Default: def, Min: 2, Integer: true}
+}
+
+// closeIndicator описывает индикатор talib от close с одним периодом.
+func closeIndicator(name, title string, pane Pane, lookback func(int) int, fn func([]float64, int) []float64) Indicator {
+	return Indicator{
+		Name: name, Title: title, Pane: pane,
+		Params:   []Param{length("period", 14)},
+		Outputs:  []string{name},
+		lookback: func(p []float64) int { return lookback(int(p[0])) },
+		compute: func(q quote.Quote, p []float64) [][]float64 {
+			return [][]float64{fn(q.Close, int(p[0]))}
+		},
+	}
+}
+
+// hlcIndicator описывает осциллятор talib от high, low, close с одним периодом.
+func hlcIndicator(name, title string, lookback func(int) int, fn func(high, low, close []float64, n int) []float64) Indicator {
+	return Indicator{
+		Name: name, Title: title, Pane: PaneOscillator,
+		Params:   []Param{length("period", 14)},
+		Outputs:  []string{name},
+		lookback: func(p []float64) int { return lookback(int(p[0])) },
+		compute: func(q quote.Quote, p []float64) [][]float64 {
+			return [][]float64{fn(q.High, q.Low, q.Close, int(p[0]))}
+		},
+	}
+}
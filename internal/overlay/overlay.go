@@ -0,0 +1,157 @@
+package overlay
+
+import (
+	"fmt"
+	"main/internal/model"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/markcheno/go-quote"
+)
+
+// Pane - где рисуется индикатор: поверх цены или на отдельной панели.
+type Pane string
+
+const (
+	PanePrice      Pane = "price"
+	PaneOscillator Pane = "oscillator"
+)
+
+// Param описывает числовой параметр индикатора.
+type Param struct {
+	Name    string  `json:"name"`
+	Default float64 `json:"default"`
+	Min     float64 `json:"min"`
+	Integer bool    `json:"integer"` // длины периодов - целые
+}
+
+// Indicator - описание индикатора в реестре.
+type Indicator struct {
+	Name    string   `json:"name"`
+	Title   string   `json:"title"`
+	Pane    Pane     `json:"pane"`
+	Params  []Param  `json:"params"`
+	Outputs []string `json:"outputs"`
+
+	// lookback - число первых баров без значения при параметрах p
+	lookback func(p []float64) int
+	// compute возвращает ряды в порядке Outputs; вызывается, только если баров больше lookback
+	compute func(q quote.Quote, p []float64) [][]float64
+}
+
+var registry = map[string]Indicator{}
+
+func register(ind Indicator) {
+	if _, exists := registry[ind.Name]; exists {
+		panic(fmt.Sprintf("indicator %q registered twice", ind.Name))
+	}
+	registry[ind.Name] = ind
+}
+
+// Indicators возвращает реестр индикаторов, упорядоченный по имени.
+func Indicators() []Indicator {
+	list := make([]Indicator, 0, len(registry))
+	for _, ind := range registry {
+		list = append(list, ind)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Request - индикатор из запроса клиента; отсутствующие параметры берутся по умолчанию.
+type Request struct {
+	Name   string             `json:"name"`
+	Params map[string]float64 `json:"params"`
+}
+
+// Overlay - рассчитанный индикатор; ряды выровнены по барам котировки, прогрев - NaN.
+type Overlay struct {
+	ID     string                  `json:"id"` // запись с параметрами, например "macd(12,26,9)"
+	Name   string                  `json:"name"`
+	Pane   Pane                    `json:"pane"`
+	Params map[string]float64      `json:"params"`
+	Series map[string]model.Series `json:"series"`
+}
+
+// Compute считает индикатор req по котировке q.
+func Compute(q quote.Quote, req Request) (Overlay, error) {
+	ind, ok := registry[req.Name]
+	if !ok {
+		names := make([]string, 0, len(registry))
+		for _, ind := range Indicators() {
+			names = append(names, ind.Name)
+		}
+		return Overlay{}, fmt.Errorf("unknown indicator %q, available: %s", req.Name, strings.Join(names, ", "))
+	}
+	params, err := ind.resolve(req.Params)
+	if err != nil {
+		return Overlay{}, err
+	}
+
+	n := len(q.Close)
+	var outputs [][]float64
+	if lookback := ind.lookback(params); n > lookback {
+		outputs = ind.compute(q, params)
+		for _, out := range outputs {
+			for i := 0; i < lookback; i++ {
+				out[i] = math.NaN()
+			}
+		}
+	} else {
+		for range ind.Outputs {
+			outputs = append(outputs, nanSeries(n))
+		}
+	}
+
+	overlay := Overlay{
+		Name:   ind.Name,
+		Pane:   ind.Pane,
+		Params: make(map[string]float64, len(params)),
+		Series: make(map[string]model.Series, len(outputs)),
+	}
+	args := make([]string, len(params))
+	for i, p := range ind.Params {
+		overlay.Params[p.Name] = params[i]
+		args[i] = strconv.FormatFloat(params[i], 'f', -1, 64)
+	}
+	overlay.ID = ind.Name + "(" + strings.Join(args, ",") + ")"
+	for i, name := range ind.Outputs {
+		overlay.Series[name] = outputs[i]
+	}
+	return overlay, nil
+}
+
+// resolve проверяет параметры запроса и возвращает их в порядке Params.
+func (ind Indicator) resolve(values map[string]float64) ([]float64, error) {
+	for name := range values {
+		if !slices.ContainsFunc(ind.Params, func(p Param) bool { return p.Name == name }) {
+			return nil, fmt.Errorf("%s: unknown parameter %q", ind.Name, name)
+		}
+	}
+	params := make([]float64, len(ind.Params))
+	for i, p := range ind.Params {
+		v, ok := values[p.Name]
+		if !ok {
+			v = p.Default
+		}
+		if math.IsNaN(v) || v < p.Min {
+			return nil, fmt.Errorf("%s: %s must be at least %v, got %v", ind.Name, p.Name, p.Min, v)
+		}
+		if p.Integer && v != math.Trunc(v) {
+			return nil, fmt.Errorf("%s: %s must be an integer, got %v", ind.Name, p.Name, v)
+		}
+		params[i] = v
+	}
+	return params, nil
+}
+
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package overlay
+
+import (
+	"encoding/json"
+	"main/internal/model"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// testQuote строит n часовых баров с колеблющейся ценой и объёмом.
+func testQuote(n int) quote.Quote {
+	q := quote.NewQuote("TEST", 0)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range n {
+		c := 100 + 10*math.Sin(float64(i)/5) + float64(i%7)
+		q.Date = append(q.Date, start.Add(time.Duration(i)*time.Hour))
+		q.Open = append(q.Open, c-1)
+		q.High = append(q.High, c+2)
+		q.Low = append(q.Low, c-2)
+		q.Close = append(q.Close, c)
+		q.Volume = append(q.Volume, float64(100+i%13))
+	}
+	return q
+}
+
+func TestIndicatorWarmup(t *testing.T) {
+	// баров прогрева при параметрах по умолчанию
+	warmup := map[string]int{
+		"sma": 13, "ema": 13, "wma": 13, "bbands": 19, "sar": 1,
+		"rsi": 14, "mom": 14, "roc": 14, "stddev": 19, "macd": 33, "stoch": 17,
+		"atr": 14, "adx": 27, "cci": 13, "willr": 13, "mfi": 14, "obv": 0,
+	}
+	const n = 100
+	q := testQuote(n)
+	indicators := Indicators()
+	if len(indicators) != len(warmup) {
+		t.Errorf("registry has %d indicators, the test covers %d", len(indicators), len(warmup))
+	}
+	for _, ind := range indicators {
+		t.Run(ind.Name, func(t *testing.T) {
+			want, ok := warmup[ind.Name]
+			if !ok {
+				t.Fatal("no expected warmup")
+			}
+			overlay, err := Compute(q, Request{Name: ind.Name})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(overlay.Series) != len(ind.Outputs) {
+				t.Fatalf("got %d series, want %d", len(overlay.Series), len(ind.Outputs))
+			}
+			for _, name := range ind.Outputs {
+				s := overlay.Series[name]
+				if len(s) != n {
+					t.Fatalf("%s: length %d, want %d", name, len(s), n)
+				}
+				for i, v := range s {
+					if warm := i < want; warm != math.IsNaN(v) {
+						t.Fatalf("%s[%d] = %v, want warmup for the first %d bars only", name, i, v, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestComputeTooFewBars(t *testing.T) {
+	overlay, err := Compute(testQuote(20), Request{Name: "macd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range overlay.Series {
+		if len(s) != 20 {
+			t.Errorf("%s: length %d, want 20", name, len(s))
+		}
+		for i, v := range s {
+			if !math.IsNaN(v) {
+				t.Errorf("%s[%d] = %v, want NaN", name, i, v)
+			}
+		}
+	}
+}
+
+func TestComputeParams(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		id   string
+		err  string
+	}{
+		{"defaults", Request{Name: "macd"}, "macd(12,26,9)", ""},
+		{"partial", Request{Name: "bbands", Params: map[string]float64{"devUp": 2.5}}, "bbands(20,2.5,2)", ""},
+		{"unknown indicator", Request{Name: "vwap"}, "", `unknown indicator "vwap", available: adx, atr`},
+		{"unknown parameter", Request{Name: "sma", Params: map[string]float64{"length": 5}}, "", `sma: unknown parameter "length"`},
+		{"below minimum", Request{Name: "sma", Params: map[string]float64{"period": 1}}, "", "sma: period must be at least 2, got 1"},
+		{"not an integer", Request{Name: "rsi", Params: map[string]float64{"period": 14.5}}, "", "rsi: period must be an integer, got 14.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overlay, err := Compute(testQuote(50), tt.req)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if overlay.ID != tt.id {
+				t.Errorf("id = %q, want %q", overlay.ID, tt.id)
+			}
+		})
+	}
+
+	// период меняет прогрев
+	overlay, err := Compute(testQuote(50), Request{Name: "sma", Params: map[string]float64{"period": 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := overlay.Series["sma"]; !math.IsNaN(s[3]) || math.IsNaN(s[4]) {
+		t.Errorf("sma(5) warmup = %v, want 4 bars", s[:6])
+	}
+}
+
+func TestOverlayJSON(t *testing.T) {
+	overlay := Overlay{
+		ID:     "test(1)",
+		Series: map[string]model.Series{"v": {math.NaN(), 1.5, math.Inf(1), -2, math.Inf(-1)}},
+	}
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"v":[null,1.5,null,-2,null]`) {
+		t.Errorf("json = %s", data)
+	}
+
+	var decoded struct {
+		Series map[string][]*float64 `json:"series"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	got := decoded.Series["v"]
+	if len(got) != 5 || got[0] != nil || *got[1] != 1.5 || got[2] != nil || *got[3] != -2 || got[4] != nil {
+		t.Errorf("decoded = %v", got)
+	}
+
+	if data, _ := json.Marshal(model.Series(nil)); string(data) != "null" {
+		t.Errorf("nil series = %s, want null", data)
+	}
+	if data, _ := json.Marshal(model.Series{}); string(data) != "[]" {
+		t.Errorf("empty series = %s, want []", data)
+	}
+}
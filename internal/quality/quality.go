@@ -0,0 +1,332 @@
+package quality
+
+import (
+	"fmt"
+	"main/internal/utils"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// Виды проблем в котировках
+const (
+	IssueUnsorted     = "unsorted"
+	IssueDuplicate    = "duplicate"
+	IssueInvalidPrice = "invalid_price"
+	IssueInconsistent = "inconsistent"
+	IssueGap          = "gap"
+	IssueOutlier      = "outlier"
+)
+
+// maxIssues ограничивает список проблем в отчёте, счётчики считаются полностью.
+const maxIssues = 100
+
+// Policy задаёт, какие исправления применять к котировкам.
+type Policy struct {
+	Sort        bool // упорядочить бары по дате
+	Dedupe      bool // оставить последний из баров с одинаковой датой
+	DropInvalid bool // удалить бары с неположительными/NaN ценами и high < low
+	FillGaps    bool // заполнить пропуски барами с ценой предыдущего закрытия
+	// MaxFillBars - сколько баров FillGaps вставляет в один пропуск, более длинные
+	// пропуски только попадают в отчёт
+	MaxFillBars int
+	// OutlierSigma - порог выброса в робастных сигмах логарифмической доходности, 0 - не искать
+	OutlierSigma float64
+	DropOutliers bool
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		Sort:         true,
+		Dedupe:       true,
+		DropInvalid:  true,
+		MaxFillBars:  100,
+		OutlierSigma: 10,
+	}
+}
+
+// PolicyFromEnv читает QUOTE_REPAIR (список из sort, dedupe, drop, ffill, drop_outliers или none),
+// QUOTE_FILL_LIMIT и QUOTE_OUTLIER_SIGMA.
+func PolicyFromEnv() (Policy, error) {
+	policy := DefaultPolicy()
+
+	if v := os.Getenv("QUOTE_REPAIR"); v != "" {
+		policy = Policy{MaxFillBars: policy.MaxFillBars, OutlierSigma: policy.OutlierSigma}
+		for _, action := range strings.Split(v, ",") {
+			switch strings.ToLower(strings.TrimSpace(action)) {
+			case "none", "":
+			case "sort":
+				policy.Sort = true
+			case "dedupe":
+				policy.Dedupe = true
+			case "drop":
+				policy.DropInvalid = true
+			case "ffill":
+				policy.FillGaps = true
+			case "drop_outliers":
+				policy.DropOutliers = true
+			default:
+				return policy, fmt.Errorf("unknown QUOTE_REPAIR action %q", action)
+			}
+		}
+	}
+	if v := os.Getenv("QUOTE_FILL_LIMIT"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return policy, fmt.Errorf("invalid QUOTE_FILL_LIMIT %q", v)
+		}
+		policy.MaxFillBars = limit
+	}
+	if v := os.Getenv("QUOTE_OUTLIER_SIGMA"); v != "" {
+		sigma, err := strconv.ParseFloat(v, 64)
+		if err != nil || sigma < 0 {
+			return policy, fmt.Errorf("invalid QUOTE_OUTLIER_SIGMA %q", v)
+		}
+		policy.OutlierSigma = sigma
+	}
+	return policy, nil
+}
+
+type Issue struct {
+	Kind    string    `json:"kind"`
+	Date    time.Time `json:"date"`
+	Message string    `json:"message"`
+}
+
+type Report struct {
+	Bars          int      `json:"bars"`      // баров на входе
+	BarsAfter     int      `json:"barsAfter"` // баров после исправлений
+	Unsorted      int      `json:"unsorted"`
+	Duplicates    int      `json:"duplicates"`
+	InvalidPrices int      `json:"invalidPrices"`
+	Inconsistent  int      `json:"inconsistent"`
+	Gaps          int      `json:"gaps"`
+	MissingBars   int      `json:"missingBars"`
+	Outliers      int      `json:"outliers"`
+	Actions       []string `json:"actions"`
+	Issues        []Issue  `json:"issues"`
+}
+
+// OK сообщает, что проблем не найдено.
+func (r Report) OK() bool {
+	return r.Unsorted+r.Duplicates+r.InvalidPrices+r.Inconsistent+r.Gaps+r.Outliers == 0
+}
+
+func (r *Report) add(kind string, date time.Time, format string, args ...any) {
+	if len(r.Issues) < maxIssues {
+		r.Issues = append(r.Issues, Issue{Kind: kind, Date: date, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// Check проверяет котировки и применяет исправления из policy. Проверки идут по порядку:
+// сортировка, дубликаты, некорректные цены, выбросы, пропуски - каждая видит
+// результат предыдущих исправлений. Массивы OHLCV разной длины исправить нельзя,
+// для них возвращается ошибка.
+func Check(q quote.Quote, period quote.Period, policy Policy) (quote.Quote, Report, error) {
+	report := Report{Bars: len(q.Date), Actions: []string{}, Issues: []Issue{}}
+	if err := utils.CheckLengths(q); err != nil {
+		return q, report, fmt.Errorf("malformed %s quote: %w", q.Symbol, err)
+	}
+
+	for i := 1; i < len(q.Date); i++ {
+		if q.Date[i].Before(q.Date[i-1]) {
+			report.Unsorted++
+			report.add(IssueUnsorted, q.Date[i], "bar is earlier than previous bar %s", q.Date[i-1].Format(time.RFC3339))
+		}
+	}
+	if report.Unsorted > 0 && policy.Sort {
+		q = utils.SortQuote(q)
+		report.Actions = append(report.Actions, "sort")
+	}
+
+	q = checkDuplicates(q, policy, &report)
+	q = checkPrices(q, policy, &report)
+	if policy.OutlierSigma > 0 {
+		q = checkOutliers(q, policy, &report)
+	}
+	q = checkGaps(q, period, policy, &report)
+
+	report.BarsAfter = len(q.Date)
+	return q, report, nil
+}
+
+func checkDuplicates(q quote.Quote, policy Policy, report *Report) quote.Quote {
+	keep := make([]bool, len(q.Date))
+	last := make(map[int64]int, len(q.Date))
+	for i, d := range q.Date {
+		if j, ok := last[d.UnixNano()]; ok {
+			report.Duplicates++
+			report.add(IssueDuplicate, d, "duplicate timestamp")
+			keep[j] = false
+		}
+		last[d.UnixNano()] = i
+		keep[i] = true
+	}
+	if report.Duplicates == 0 || !policy.Dedupe {
+		return q
+	}
+	report.Actions = append(report.Actions, "dedupe")
+	return filter(q, keep)
+}
+
+func checkPrices(q quote.Quote, policy Policy, report *Report) quote.Quote {
+	keep := make([]bool, len(q.Date))
+	dropped := false
+	for i, d := range q.Date {
+		keep[i] = true
+		prices := []float64{q.Open[i], q.High[i], q.Low[i], q.Close[i]}
+		invalid := q.Volume[i] < 0 || math.IsNaN(q.Volume[i])
+		for _, p := range prices {
+			if p <= 0 || math.IsNaN(p) || math.IsInf(p, 0) {
+				invalid = true
+			}
+		}
+		if invalid {
+			report.InvalidPrices++
+			report.add(IssueInvalidPrice, d, "non-positive or non-finite value: o=%g h=%g l=%g c=%g v=%g",
+				q.Open[i], q.High[i], q.Low[i], q.Close[i], q.Volume[i])
+		} else if q.High[i] < q.Low[i] ||
+			q.Open[i] > q.High[i] || q.Open[i] < q.Low[i] ||
+			q.Close[i] > q.High[i] || q.Close[i] < q.Low[i] {
+			invalid = true
+			report.Inconsistent++
+			report.add(IssueInconsistent, d, "open/close outside high/low: o=%g h=%g l=%g c=%g",
+				q.Open[i], q.High[i], q.Low[i], q.Close[i])
+		}
+		if invalid && policy.DropInvalid {
+			keep[i] = false
+			dropped = true
+		}
+	}
+	if !dropped {
+		return q
+	}
+	report.Actions = append(report.Actions, "drop")
+	return filter(q, keep)
+}
+
+// checkOutliers ищет бары, доходность которых отличается от медианы больше чем
+// на OutlierSigma робастных сигм (1.4826 * MAD).
+func checkOutliers(q quote.Quote, policy Policy, report *Report) quote.Quote {
+	if len(q.Close) < 3 {
+		return q
+	}
+	returns := make([]float64, len(q.Close)-1)
+	for i := 1; i < len(q.Close); i++ {
+		if q.Close[i] > 0 && q.Close[i-1] > 0 {
+			returns[i-1] = math.Log(q.Close[i] / q.Close[i-1])
+		}
+	}
+	med := median(returns)
+	deviations := make([]float64, len(returns))
+	for i, r := range returns {
+		deviations[i] = math.Abs(r - med)
+	}
+	sigma := 1.4826 * median(deviations)
+	if sigma == 0 || math.IsNaN(sigma) {
+		return q
+	}
+
+	keep := make([]bool, len(q.Date))
+	for i := range keep {
+		keep[i] = true
+	}
+	dropped := false
+	for i, r := range returns {
+		score := math.Abs(r-med) / sigma
+		if score <= policy.OutlierSigma {
+			continue
+		}
+		report.Outliers++
+		report.add(IssueOutlier, q.Date[i+1], "close %g moved %.1f%% from %g (%.1f sigma)",
+			q.Close[i+1], (math.Exp(r)-1)*100, q.Close[i], score)
+		// бар, после которого цена сразу вернулась, считаем ошибочным тиком
+		if policy.DropOutliers && i+1 < len(returns) && r*returns[i+1] < 0 &&
+			math.Abs(returns[i+1]-med)/sigma > policy.OutlierSigma {
+			keep[i+1] = false
+			dropped = true
+		}
+	}
+	if !dropped {
+		return q
+	}
+	report.Actions = append(report.Actions, "drop_outliers")
+	return filter(q, keep)
+}
+
+func checkGaps(q quote.Quote, period quote.Period, policy Policy, report *Report) quote.Quote {
+	// месячные бары имеют разную длину, пропуски в них не ищем
+	if period == quote.Monthly || len(q.Date) < 2 {
+		return q
+	}
+	step := utils.PeriodDuration(period)
+
+	out := quote.NewQuote(q.Symbol, 0)
+	out.Precision = q.Precision
+	filled := false
+	for i := range q.Date {
+		if i > 0 {
+			prev := q.Date[i-1]
+			if diff := q.Date[i].Sub(prev); diff > step+step/2 {
+				missing := int(diff/step) - 1
+				fill := policy.FillGaps && missing <= policy.MaxFillBars
+				report.Gaps++
+				report.MissingBars += missing
+				if policy.FillGaps && !fill {
+					report.add(IssueGap, prev.Add(step), "%d bars missing before %s, too many to fill",
+						missing, q.Date[i].Format(time.RFC3339))
+				} else {
+					report.add(IssueGap, prev.Add(step), "%d bars missing before %s", missing, q.Date[i].Format(time.RFC3339))
+				}
+				if fill {
+					last := len(out.Close) - 1
+					for t := prev.Add(step); q.Date[i].Sub(t) >= step/2; t = t.Add(step) {
+						c := out.Close[last]
+						out.Date = append(out.Date, t)
+						out.Open = append(out.Open, c)
+						out.High = append(out.High, c)
+						out.Low = append(out.Low, c)
+						out.Close = append(out.Close, c)
+						out.Volume = append(out.Volume, 0)
+					}
+					filled = true
+				}
+			}
+		}
+		utils.AppendBar(&out, q, i)
+	}
+	if !filled {
+		return q
+	}
+	report.Actions = append(report.Actions, "ffill")
+	return out
+}
+
+func filter(q quote.Quote, keep []bool) quote.Quote {
+	out := quote.NewQuote(q.Symbol, 0)
+	out.Precision = q.Precision
+	for i := range q.Date {
+		if keep[i] {
+			utils.AppendBar(&out, q, i)
+		}
+	}
+	return out
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
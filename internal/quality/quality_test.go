@@ -0,0 +1,162 @@
+package quality
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// hourly строит бары по часам дня 2024-01-01 с open = high = low = close.
+func hourly(hours []int, closes ...float64) quote.Quote {
+	q := quote.NewQuote("TEST", 0)
+	for i, c := range closes {
+		q.Date = append(q.Date, at("2024-01-01 00:00").Add(time.Duration(hours[i])*time.Hour))
+		q.Open = append(q.Open, c)
+		q.High = append(q.High, c)
+		q.Low = append(q.Low, c)
+		q.Close = append(q.Close, c)
+		q.Volume = append(q.Volume, 1)
+	}
+	return q
+}
+
+func TestDedupeKeepsLast(t *testing.T) {
+	q := hourly([]int{0, 1, 2, 2, 3}, 10, 11, 12, 15, 13)
+
+	got, report, err := Check(q, quote.Min60, Policy{Dedupe: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Duplicates != 1 || !slices.Contains(report.Actions, "dedupe") {
+		t.Errorf("report = %+v, want one duplicate and the dedupe action", report)
+	}
+	if want := []float64{10, 11, 15, 13}; !slices.Equal(got.Close, want) {
+		t.Errorf("closes = %v, want %v", got.Close, want)
+	}
+
+	// без исправления дубликат только попадает в отчёт
+	got, report, _ = Check(q, quote.Min60, Policy{})
+	if report.Duplicates != 1 || len(got.Date) != 5 || len(report.Actions) != 0 {
+		t.Errorf("got %d bars, report = %+v", len(got.Date), report)
+	}
+}
+
+func TestFillGaps(t *testing.T) {
+	// между 01:00 и 05:00 не хватает трёх баров
+	q := hourly([]int{0, 1, 5, 6}, 10, 11, 15, 16)
+	tests := []struct {
+		name   string
+		policy Policy
+		closes []float64
+		volume float64 // объём вставленных баров
+	}{
+		{"fills up to the limit", Policy{FillGaps: true, MaxFillBars: 3}, []float64{10, 11, 11, 11, 11, 15, 16}, 0},
+		{"gap longer than the limit", Policy{FillGaps: true, MaxFillBars: 2}, []float64{10, 11, 15, 16}, 1},
+		{"report only", Policy{MaxFillBars: 3}, []float64{10, 11, 15, 16}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, report, err := Check(q, quote.Min60, tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Gaps != 1 || report.MissingBars != 3 {
+				t.Errorf("gaps = %d, missing = %d, want 1, 3", report.Gaps, report.MissingBars)
+			}
+			if !slices.Equal(got.Close, tt.closes) {
+				t.Errorf("closes = %v, want %v", got.Close, tt.closes)
+			}
+			if got.Volume[2] != tt.volume || report.BarsAfter != len(tt.closes) {
+				t.Errorf("volume = %v, bars after = %d", got.Volume[2], report.BarsAfter)
+			}
+			tooMany := strings.Contains(report.Issues[0].Message, "too many to fill")
+			if tooMany != (tt.policy.FillGaps && len(tt.closes) == 4) {
+				t.Errorf("issue = %q", report.Issues[0].Message)
+			}
+		})
+	}
+}
+
+func TestOutliers(t *testing.T) {
+	hours := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	tests := []struct {
+		name     string
+		closes   []float64
+		outliers int
+		fixed    []float64 // после исправления
+	}{
+		// цена сразу вернулась: ошибочный тик удаляется
+		{"spike with reversal", []float64{100, 101, 100, 101, 200, 101, 100, 101, 100, 101}, 2,
+			[]float64{100, 101, 100, 101, 101, 100, 101, 100, 101}},
+		// цена осталась на новом уровне: скачок только попадает в отчёт
+		{"level shift", []float64{100, 101, 100, 101, 200, 201, 200, 201, 200, 201}, 1,
+			[]float64{100, 101, 100, 101, 200, 201, 200, 201, 200, 201}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, report, err := Check(hourly(hours, tt.closes...), quote.Min60, Policy{OutlierSigma: 10, DropOutliers: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Outliers != tt.outliers {
+				t.Errorf("outliers = %d, want %d", report.Outliers, tt.outliers)
+			}
+			if !slices.Equal(got.Close, tt.fixed) {
+				t.Errorf("closes = %v, want %v", got.Close, tt.fixed)
+			}
+		})
+	}
+}
+
+func TestMismatchedLengths(t *testing.T) {
+	q := hourly([]int{0, 1, 2}, 10, 11, 12)
+	q.Volume = q.Volume[:2]
+	if _, _, err := Check(q, quote.Min60, DefaultPolicy()); err == nil {
+		t.Error("expected an error for OHLCV arrays of different length")
+	}
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    Policy
+		wantErr bool
+	}{
+		{"defaults", nil, DefaultPolicy(), false},
+		// список действий заменяет исправления по умолчанию, пороги остаются
+		{"actions", map[string]string{"QUOTE_REPAIR": "Sort, ffill,drop_outliers"},
+			Policy{Sort: true, FillGaps: true, DropOutliers: true, MaxFillBars: 100, OutlierSigma: 10}, false},
+		{"none", map[string]string{"QUOTE_REPAIR": "none"}, Policy{MaxFillBars: 100, OutlierSigma: 10}, false},
+		{"limits", map[string]string{"QUOTE_FILL_LIMIT": "5", "QUOTE_OUTLIER_SIGMA": "0"},
+			Policy{Sort: true, Dedupe: true, DropInvalid: true, MaxFillBars: 5}, false},
+		{"unknown action", map[string]string{"QUOTE_REPAIR": "sort,fix"}, Policy{}, true},
+		{"negative fill limit", map[string]string{"QUOTE_FILL_LIMIT": "-1"}, Policy{}, true},
+		{"bad sigma", map[string]string{"QUOTE_OUTLIER_SIGMA": "high"}, Policy{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"QUOTE_REPAIR", "QUOTE_FILL_LIMIT", "QUOTE_OUTLIER_SIGMA"} {
+				t.Setenv(key, tt.env[key])
+			}
+			got, err := PolicyFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("policy = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package resample
+
+import (
+	"fmt"
+	"main/internal/utils"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+const day = 24 * time.Hour
+
+// Options задаёт выравнивание и обработку неполных баров.
+type Options struct {
+	// Location - часовой пояс, в котором считаются границы суток, недель и месяцев.
+	// nil - используется часовой пояс каждого бара
+	Location *time.Location
+	// SessionOffset - сдвиг начала торговой сессии от полуночи (например, 17h для форекса)
+	SessionOffset time.Duration
+	// WeekStart - первый день недели для недельных баров, по умолчанию понедельник
+	WeekStart *time.Weekday
+	// DropPartial - отбрасывать первый и последний бары, если исходных данных не хватает на полный период
+	DropPartial bool
+	// Base - шаг исходных баров, если он известен заранее; 0 - определяется по датам
+	Base time.Duration
+}
+
+// DetectPeriod определяет шаг данных как минимальный положительный интервал между барами.
+func DetectPeriod(q quote.Quote) time.Duration {
+	var step time.Duration
+	for i := 1; i < len(q.Date); i++ {
+		d := q.Date[i].Sub(q.Date[i-1])
+		if d > 0 && (step == 0 || d < step) {
+			step = d
+		}
+	}
+	return step
+}
+
+// CanResample сообщает, можно ли собрать бары target из баров base без остатка.
+func CanResample(base time.Duration, target quote.Period) bool {
+	if base <= 0 {
+		return false
+	}
+	switch target {
+	case quote.Weekly, quote.Monthly:
+		return base <= day && day%base == 0
+	}
+	t := utils.PeriodDuration(target)
+	return t >= base && t%base == 0
+}
+
+// Resample агрегирует бары q в период target: open первого бара, максимум high,
+// минимум low, close последнего бара и сумма объёмов. Даты баров - начало периода.
+// Если данные уже в нужном периоде, они возвращаются без изменений. Шаг одного бара
+// определить нельзя, поэтому для него нужен Options.Base.
+func Resample(q quote.Quote, target quote.Period, opts Options) (quote.Quote, error) {
+	if len(q.Date) == 0 {
+		out := quote.NewQuote(q.Symbol, 0)
+		out.Precision = q.Precision
+		return out, nil
+	}
+	base := opts.Base
+	if base == 0 {
+		if len(q.Date) < 2 {
+			return quote.Quote{}, fmt.Errorf("cannot detect the period of a single bar")
+		}
+		base = DetectPeriod(q)
+	}
+	if base == utils.PeriodDuration(target) && target != quote.Monthly {
+		return q, nil
+	}
+	if !CanResample(base, target) {
+		return quote.Quote{}, fmt.Errorf("cannot build period %s from %s bars", target, base)
+	}
+
+	out := quote.NewQuote(q.Symbol, 0)
+	out.Precision = q.Precision
+	var bucket time.Time
+	for i, d := range q.Date {
+		b := opts.bucketStart(d, target)
+		last := len(out.Date) - 1
+		if last < 0 || !b.Equal(bucket) {
+			bucket = b
+			out.Date = append(out.Date, b)
+			out.Open = append(out.Open, q.Open[i])
+			out.High = append(out.High, q.High[i])
+			out.Low = append(out.Low, q.Low[i])
+			out.Close = append(out.Close, q.Close[i])
+			out.Volume = append(out.Volume, q.Volume[i])
+			continue
+		}
+		if q.High[i] > out.High[last] {
+			out.High[last] = q.High[i]
+		}
+		if q.Low[i] < out.Low[last] {
+			out.Low[last] = q.Low[i]
+		}
+		out.Close[last] = q.Close[i]
+		out.Volume[last] += q.Volume[i]
+	}
+
+	if opts.DropPartial && len(out.Date) > 0 {
+		out = opts.dropPartial(out, q, target, base)
+	}
+	return out, nil
+}
+
+// dropPartial убирает первый бар, если данные начинаются позже начала периода,
+// и последний, если данные заканчиваются раньше его конца.
+func (o Options) dropPartial(out, src quote.Quote, target quote.Period, base time.Duration) quote.Quote {
+	from, to := 0, len(out.Date)
+	if src.Date[0].After(out.Date[0]) {
+		from++
+	}
+	lastEnd := src.Date[len(src.Date)-1].Add(base)
+	if to > from && lastEnd.Before(o.bucketEnd(out.Date[to-1], target)) {
+		to--
+	}
+	if from == 0 && to == len(out.Date) {
+		return out
+	}
+
+	trimmed := quote.NewQuote(out.Symbol, 0)
+	trimmed.Precision = out.Precision
+	for i := from; i < to; i++ {
+		utils.AppendBar(&trimmed, out, i)
+	}
+	return trimmed
+}
+
+func (o Options) bucketStart(t time.Time, target quote.Period) time.Time {
+	if o.Location != nil {
+		t = t.In(o.Location)
+	}
+	// сдвигаем время так, чтобы начало сессии совпало с полуночью
+	s := t.Add(-o.SessionOffset)
+	midnight := time.Date(s.Year(), s.Month(), s.Day(), 0, 0, 0, 0, s.Location())
+
+	var start time.Time
+	switch target {
+	case quote.Monthly:
+		start = time.Date(s.Year(), s.Month(), 1, 0, 0, 0, 0, s.Location())
+	case quote.Weekly:
+		weekStart := time.Monday
+		if o.WeekStart != nil {
+			weekStart = *o.WeekStart
+		}
+		offset := (int(midnight.Weekday()) - int(weekStart) + 7) % 7
+		start = midnight.AddDate(0, 0, -offset)
+	default:
+		d := utils.PeriodDuration(target)
+		if d >= day {
+			days := int(d / day)
+			epoch := time.Date(1970, 1, 1, 0, 0, 0, 0, s.Location())
+			n := int(midnight.Sub(epoch).Round(day) / day)
+			start = midnight.AddDate(0, 0, -(n % days))
+		} else {
+			start = midnight.Add(s.Sub(midnight).Truncate(d))
+		}
+	}
+	return start.Add(o.SessionOffset)
+}
+
+func (o Options) bucketEnd(start time.Time, target quote.Period) time.Time {
+	switch target {
+	case quote.Monthly:
+		return start.AddDate(0, 1, 0)
+	case quote.Weekly:
+		return start.AddDate(0, 0, 7)
+	}
+	d := utils.PeriodDuration(target)
+	if d >= day {
+		return start.AddDate(0, 0, int(d/day))
+	}
+	return start.Add(d)
+}
+
+// BarStart возвращает начало бара периода p, в который попадает момент t (UTC).
+func BarStart(t time.Time, p quote.Period) time.Time {
+	return Options{Location: time.UTC}.bucketStart(t, p)
+}
+
+// BarEnd возвращает момент закрытия бара периода p, начавшегося в start.
+func BarEnd(start time.Time, p quote.Period) time.Time {
+	return Options{}.bucketEnd(start, p)
+}
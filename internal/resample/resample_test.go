@@ -0,0 +1,228 @@
+package resample
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// bars строит n баров с шагом step: бар i - open 10+i, high 20+i, low i, close 15+i, объём 1+i.
+func bars(start string, step time.Duration, n int) quote.Quote {
+	q := quote.NewQuote("TEST", 0)
+	q.Precision = 2
+	t := at(start)
+	for i := range n {
+		f := float64(i)
+		q.Date = append(q.Date, t.Add(time.Duration(i)*step))
+		q.Open = append(q.Open, 10+f)
+		q.High = append(q.High, 20+f)
+		q.Low = append(q.Low, f)
+		q.Close = append(q.Close, 15+f)
+		q.Volume = append(q.Volume, 1+f)
+	}
+	return q
+}
+
+// bar - ожидаемый бар: дата и open, high, low, close, volume.
+type bar struct {
+	date  string
+	ohlcv [5]float64
+}
+
+func check(t *testing.T, got quote.Quote, want []bar) {
+	t.Helper()
+	if len(got.Date) != len(want) {
+		t.Fatalf("got %d bars %v, want %d", len(got.Date), got.Date, len(want))
+	}
+	for i, w := range want {
+		ohlcv := [5]float64{got.Open[i], got.High[i], got.Low[i], got.Close[i], got.Volume[i]}
+		if !got.Date[i].Equal(at(w.date)) || ohlcv != w.ohlcv {
+			t.Errorf("bar %d = %s %v, want %s %v", i, got.Date[i].UTC().Format("2006-01-02 15:04"), ohlcv, w.date, w.ohlcv)
+		}
+	}
+}
+
+func TestResample(t *testing.T) {
+	sunday := time.Sunday
+	tests := []struct {
+		name   string
+		q      quote.Quote
+		target quote.Period
+		opts   Options
+		want   []bar
+	}{
+		// 6 баров по 15 минут: полный час и половина следующего
+		{"15m to 1h with partial last bar", bars("2024-01-01 00:00", 15*time.Minute, 6), quote.Min60, Options{}, []bar{
+			{"2024-01-01 00:00", [5]float64{10, 23, 0, 18, 10}},
+			{"2024-01-01 01:00", [5]float64{14, 25, 4, 20, 11}},
+		}},
+		{"partial last bar dropped", bars("2024-01-01 00:00", 15*time.Minute, 6), quote.Min60, Options{DropPartial: true}, []bar{
+			{"2024-01-01 00:00", [5]float64{10, 23, 0, 18, 10}},
+		}},
+		// данные с 00:30: первый час неполный
+		{"partial first bar dropped", bars("2024-01-01 00:30", 15*time.Minute, 6), quote.Min60, Options{DropPartial: true}, []bar{
+			{"2024-01-01 01:00", [5]float64{12, 25, 2, 20, 18}},
+		}},
+		{"partial first bar kept", bars("2024-01-01 00:30", 15*time.Minute, 6), quote.Min60, Options{}, []bar{
+			{"2024-01-01 00:00", [5]float64{10, 21, 0, 16, 3}},
+			{"2024-01-01 01:00", [5]float64{12, 25, 2, 20, 18}},
+		}},
+		// 2024-01-03 - среда, неделя начинается с понедельника 2024-01-01
+		{"weekly from monday", bars("2024-01-03 00:00", day, 7), quote.Weekly, Options{}, []bar{
+			{"2024-01-01 00:00", [5]float64{10, 24, 0, 19, 15}},
+			{"2024-01-08 00:00", [5]float64{15, 26, 5, 21, 13}},
+		}},
+		{"weekly from sunday", bars("2024-01-03 00:00", day, 7), quote.Weekly, Options{WeekStart: &sunday}, []bar{
+			{"2023-12-31 00:00", [5]float64{10, 23, 0, 18, 10}},
+			{"2024-01-07 00:00", [5]float64{14, 26, 4, 21, 18}},
+		}},
+		{"monthly", bars("2024-01-30 00:00", day, 4), quote.Monthly, Options{}, []bar{
+			{"2024-01-01 00:00", [5]float64{10, 21, 0, 16, 3}},
+			{"2024-02-01 00:00", [5]float64{12, 23, 2, 18, 7}},
+		}},
+		// сутки считаются от эпохи: 2024-01-01 - второй день трёхдневного бара
+		{"three days from epoch", bars("2024-01-01 00:00", day, 3), quote.Day3, Options{}, []bar{
+			{"2023-12-31 00:00", [5]float64{10, 21, 0, 16, 3}},
+			{"2024-01-03 00:00", [5]float64{12, 22, 2, 17, 3}},
+		}},
+		// сессия с 17:00: бар 16:00 относится к предыдущим суткам
+		{"session offset", bars("2024-01-02 15:00", time.Hour, 4), quote.Daily, Options{SessionOffset: 17 * time.Hour}, []bar{
+			{"2024-01-01 17:00", [5]float64{10, 21, 0, 16, 3}},
+			{"2024-01-02 17:00", [5]float64{12, 23, 2, 18, 7}},
+		}},
+		// в UTC+3 сутки начинаются в 21:00 UTC предыдущего дня
+		{"location", bars("2024-01-01 20:00", time.Hour, 3), quote.Daily, Options{Location: time.FixedZone("MSK", 3*3600)}, []bar{
+			{"2023-12-31 21:00", [5]float64{10, 20, 0, 15, 1}},
+			{"2024-01-01 21:00", [5]float64{11, 22, 1, 17, 5}},
+		}},
+		{"single bar with known base", bars("2024-01-01 00:15", 15*time.Minute, 1), quote.Min60, Options{Base: 15 * time.Minute}, []bar{
+			{"2024-01-01 00:00", [5]float64{10, 20, 0, 15, 1}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resample(tt.q, tt.target, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(t, got, tt.want)
+			if got.Precision != tt.q.Precision {
+				t.Errorf("precision = %d, want %d", got.Precision, tt.q.Precision)
+			}
+		})
+	}
+}
+
+func TestResampleSamePeriod(t *testing.T) {
+	q := bars("2024-01-01 00:00", time.Hour, 3)
+	got, err := Resample(q, quote.Min60, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &got.Close[0] != &q.Close[0] {
+		t.Error("data already in the target period was copied")
+	}
+}
+
+func TestResampleErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		q      quote.Quote
+		target quote.Period
+	}{
+		{"single bar without base", bars("2024-01-01 00:00", time.Hour, 1), quote.Daily},
+		{"target not a multiple", bars("2024-01-01 00:00", 45*time.Minute, 4), quote.Min60},
+		{"target shorter than base", bars("2024-01-01 00:00", time.Hour, 4), quote.Min15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Resample(tt.q, tt.target, Options{}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestCanResample(t *testing.T) {
+	tests := []struct {
+		base   time.Duration
+		target quote.Period
+		want   bool
+	}{
+		{time.Minute, quote.Min5, true},
+		{15 * time.Minute, quote.Min60, true},
+		{time.Hour, quote.Hour4, true},
+		{time.Hour, quote.Daily, true},
+		{time.Hour, quote.Weekly, true},
+		{day, quote.Monthly, true},
+		{day, quote.Day3, true},
+		{time.Hour, quote.Min60, true},
+		{45 * time.Minute, quote.Min60, false},
+		{time.Hour, quote.Min30, false},
+		{7 * time.Hour, quote.Weekly, false},
+		{7 * day, quote.Monthly, false},
+		{0, quote.Daily, false},
+	}
+	for _, tt := range tests {
+		if got := CanResample(tt.base, tt.target); got != tt.want {
+			t.Errorf("CanResample(%s, %s) = %v, want %v", tt.base, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestDetectPeriod(t *testing.T) {
+	gap := bars("2024-01-01 00:00", time.Hour, 4)
+	gap.Date[2] = at("2024-01-01 05:00")
+	gap.Date[3] = at("2024-01-01 06:00")
+	tests := []struct {
+		name string
+		q    quote.Quote
+		want time.Duration
+	}{
+		{"regular", bars("2024-01-01 00:00", 15*time.Minute, 5), 15 * time.Minute},
+		{"gap in data", gap, time.Hour},
+		{"single bar", bars("2024-01-01 00:00", time.Hour, 1), 0},
+		{"empty", quote.Quote{}, 0},
+	}
+	for _, tt := range tests {
+		if got := DetectPeriod(tt.q); got != tt.want {
+			t.Errorf("%s: DetectPeriod = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBarEnd(t *testing.T) {
+	tests := []struct {
+		start  string
+		period quote.Period
+		want   string
+	}{
+		{"2024-01-01 10:00", quote.Min15, "2024-01-01 10:15"},
+		{"2024-01-01 10:00", quote.Hour4, "2024-01-01 14:00"},
+		{"2024-01-01 00:00", quote.Daily, "2024-01-02 00:00"},
+		{"2024-01-01 00:00", quote.Weekly, "2024-01-08 00:00"},
+		// месяцы разной длины
+		{"2024-02-01 00:00", quote.Monthly, "2024-03-01 00:00"},
+		{"2024-03-01 00:00", quote.Monthly, "2024-04-01 00:00"},
+	}
+	for _, tt := range tests {
+		if got := BarEnd(at(tt.start), tt.period); !got.Equal(at(tt.want)) {
+			t.Errorf("BarEnd(%s, %s) = %s, want %s", tt.start, tt.period, got, tt.want)
+		}
+	}
+}
+
+func TestBarStart(t *testing.T) {
+	if got := BarStart(at("2024-01-01 10:37"), quote.Min15); !got.Equal(at("2024-01-01 10:30")) {
+		t.Errorf("BarStart = %s, want 10:30", got)
+	}
+}
@@ -0,0 +1,92 @@
+package rule
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Type - тип значения выражения на каждом баре.
+type Type int
+
+const (
+	Number Type = iota // число
+	Bool               // условие
+)
+
+func (t Type) String() string {
+	if t == Bool {
+		return "condition"
+	}
+	return "number"
+}
+
+type node interface {
+	position() int
+	// String возвращает каноническую запись выражения, она же ключ кэша вычислений
+	String() string
+}
+
+type number struct {
+	pos   int
+	value float64
+}
+
+type boolean struct {
+	pos   int
+	value bool
+}
+
+type ident struct {
+	pos  int
+	name string
+}
+
+type call struct {
+	pos  int
+	name string
+	args []node
+}
+
+type unary struct {
+	pos int
+	op  string
+	x   node
+}
+
+type binary struct {
+	pos  int
+	op   string
+	x, y node
+}
+
+func (n *number) position() int  { return n.pos }
+func (n *boolean) position() int { return n.pos }
+func (n *ident) position() int   { return n.pos }
+func (n *call) position() int    { return n.pos }
+func (n *unary) position() int   { return n.pos }
+func (n *binary) position() int  { return n.pos }
+
+func (n *number) String() string { return strconv.FormatFloat(n.value, 'g', -1, 64) }
+
+func (n *boolean) String() string { return strconv.FormatBool(n.value) }
+
+func (n *ident) String() string { return n.name }
+
+func (n *call) String() string {
+	args := make([]string, len(n.args))
+	for i, a := range n.args {
+		args[i] = a.String()
+	}
+	return n.name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (n *unary) String() string {
+	if n.op == "not" {
+		return "not " + n.x.String()
+	}
+	return n.op + n.x.String()
+}
+
+func (n *binary) String() string {
+	return "(" + n.x.String() + " " + n.op + " " + n.y.String() + ")"
+}
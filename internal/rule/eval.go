@@ -0,0 +1,143 @@
+package rule
+
+import (
+	"math"
+
+	"github.com/markcheno/go-quote"
+)
+
+// Evaluator вычисляет правила по котировке. Одинаковые подвыражения
+// разных правил считаются один раз.
+type Evaluator struct {
+	q          quote.Quote
+	cache      map[string][]float64
+	indicators map[string][]float64
+}
+
+func NewEvaluator(q quote.Quote) *Evaluator {
+	return &Evaluator{
+		q:          q,
+		cache:      make(map[string][]float64),
+		indicators: make(map[string][]float64),
+	}
+}
+
+// Eval возвращает значение правила на каждом баре.
+func (e *Evaluator) Eval(r *Rule) []bool {
+	values := e.eval(r.root)
+	out := make([]bool, len(values))
+	for i, v := range values {
+		out[i] = v != 0
+	}
+	return out
+}
+
+// Indicators возвращает посчитанные индикаторы по их записи, например "ema(close, 51)".
+// Значения выровнены по барам, прогрев - NaN.
+func (e *Evaluator) Indicators() map[string][]float64 {
+	return e.indicators
+}
+
+// eval возвращает ряд значений узла; условия представлены как 1 и 0.
+func (e *Evaluator) eval(n node) []float64 {
+	key := n.String()
+	if v, ok := e.cache[key]; ok {
+		return v
+	}
+
+	size := len(e.q.Close)
+	var out []float64
+	switch n := n.(type) {
+	case *number:
+		out = constant(size, n.value)
+	case *boolean:
+		out = constant(size, boolValue(n.value))
+	case *ident:
+		out = fields[n.name](e.q)
+	case *unary:
+		x := e.eval(n.x)
+		out = make([]float64, size)
+		for i := range out {
+			if n.op == "not" {
+				out[i] = boolValue(x[i] == 0)
+			} else {
+				out[i] = -x[i]
+			}
+		}
+	case *binary:
+		x, y := e.eval(n.x), e.eval(n.y)
+		out = make([]float64, size)
+		for i := range out {
+			out[i] = apply(n.op, x[i], y[i])
+		}
+	case *call:
+		f := functions[n.name]
+		sources := len(n.args) - f.lengths
+		var src [][]float64
+		for _, arg := range n.args[:sources] {
+			src = append(src, e.eval(arg))
+		}
+		lengths := make([]int, 0, f.lengths)
+		for _, arg := range n.args[sources:] {
+			lengths = append(lengths, int(arg.(*number).value))
+		}
+		out = f.compute(e.q, src, lengths)
+		if f.indicator {
+			e.indicators[key] = out
+		}
+	}
+
+	e.cache[key] = out
+	return out
+}
+
+func apply(op string, x, y float64) float64 {
+	switch op {
+	case "+":
+		return x + y
+	case "-":
+		return x - y
+	case "*":
+		return x * y
+	case "/":
+		return x / y
+	case "and":
+		return boolValue(x != 0 && y != 0)
+	case "or":
+		return boolValue(x != 0 || y != 0)
+	}
+	// сравнение с NaN (прогрев индикатора) ложно
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return 0
+	}
+	switch op {
+	case "<":
+		return boolValue(x < y)
+	case "<=":
+		return boolValue(x <= y)
+	case ">":
+		return boolValue(x > y)
+	case ">=":
+		return boolValue(x >= y)
+	case "==":
+		return boolValue(x == y)
+	case "!=":
+		return boolValue(x != y)
+	}
+	return math.NaN()
+}
+
+func constant(n int, v float64) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = v
+	}
+	return out
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,136 @@
+package rule
+
+import (
+	"math"
+
+	"github.com/markcheno/go-quote"
+	"github.com/markcheno/go-talib"
+)
+
+// maxLength - верхняя граница длины: большие константы переполняют int при расчёте.
+const maxLength = 1_000_000
+
+// function описывает встроенную функцию. Аргументы: sources рядов-чисел, затем lengths
+// целых констант от minLength до maxLength. При optSource первый ряд можно опустить, тогда берётся close.
+type function struct {
+	usage     string
+	sources   int
+	optSource bool
+	lengths   int
+	minLength int
+	result    Type
+	// indicator - значение функции отдаётся в Series для графика
+	indicator bool
+	compute   func(q quote.Quote, src [][]float64, n []int) []float64
+}
+
+var functions = map[string]function{
+	"sma":     smooth("sma([source,] length)", 2, func(in []float64, n int) []float64 { return talib.Sma(in, n) }, lookbackMinus1),
+	"ema":     smooth("ema([source,] length)", 2, func(in []float64, n int) []float64 { return talib.Ema(in, n) }, lookbackMinus1),
+	"wma":     smooth("wma([source,] length)", 2, func(in []float64, n int) []float64 { return talib.Wma(in, n) }, lookbackMinus1),
+	"rsi":     smooth("rsi([source,] length)", 2, func(in []float64, n int) []float64 { return talib.Rsi(in, n) }, lookbackN),
+	"highest": smooth("highest([source,] length)", 2, func(in []float64, n int) []float64 { return talib.Max(in, n) }, lookbackMinus1),
+	"lowest":  smooth("lowest([source,] length)", 2, func(in []float64, n int) []float64 { return talib.Min(in, n) }, lookbackMinus1),
+	"stddev":  smooth("stddev([source,] length)", 2, func(in []float64, n int) []float64 { return talib.StdDev(in, n, 1) }, lookbackMinus1),
+	"mom":     smooth("mom([source,] length)", 1, func(in []float64, n int) []float64 { return talib.Mom(in, n) }, lookbackN),
+	"roc":     smooth("roc([source,] length)", 1, func(in []float64, n int) []float64 { return talib.Roc(in, n) }, lookbackN),
+	"atr": {
+		usage: "atr(length)", lengths: 1, minLength: 1, result: Number, indicator: true,
+		compute: func(q quote.Quote, _ [][]float64, n []int) []float64 {
+			return onFinite(q.Close, n[0], func([]float64) []float64 { return talib.Atr(q.High, q.Low, q.Close, n[0]) })
+		},
+	},
+	"mfi": {
+		usage: "mfi(length)", lengths: 1, minLength: 2, result: Number, indicator: true,
+		compute: func(q quote.Quote, _ [][]float64, n []int) []float64 {
+			return onFinite(q.Close, n[0], func([]float64) []float64 { return talib.Mfi(q.High, q.Low, q.Close, q.Volume, n[0]) })
+		},
+	},
+	"prev": {
+		usage: "prev(source, bars)", sources: 1, lengths: 1, minLength: 1, result: Number,
+		compute: func(_ quote.Quote, src [][]float64, n []int) []float64 {
+			out := nanSeries(len(src[0]))
+			for i := n[0]; i < len(out); i++ {
+				out[i] = src[0][i-n[0]]
+			}
+			return out
+		},
+	},
+	"abs": {
+		usage: "abs(x)", sources: 1, result: Number,
+		compute: func(_ quote.Quote, src [][]float64, _ []int) []float64 {
+			out := make([]float64, len(src[0]))
+			for i, v := range src[0] {
+				out[i] = math.Abs(v)
+			}
+			return out
+		},
+	},
+	"cross_up": {
+		usage: "cross_up(a, b)", sources: 2, result: Bool,
+		compute: func(_ quote.Quote, src [][]float64, _ []int) []float64 {
+			return crossing(src[0], src[1], func(prevA, prevB, a, b float64) bool { return prevA <= prevB && a > b })
+		},
+	},
+	"cross_down": {
+		usage: "cross_down(a, b)", sources: 2, result: Bool,
+		compute: func(_ quote.Quote, src [][]float64, _ []int) []float64 {
+			return crossing(src[0], src[1], func(prevA, prevB, a, b float64) bool { return prevA >= prevB && a < b })
+		},
+	},
+}
+
+func lookbackN(n int) int      { return n }
+func lookbackMinus1(n int) int { return n - 1 }
+
+// smooth описывает индикатор talib от одного ряда с длиной.
+func smooth(usage string, minLength int, fn func(in []float64, n int) []float64, lookback func(int) int) function {
+	return function{
+		usage: usage, sources: 1, optSource: true, lengths: 1, minLength: minLength, result: Number, indicator: true,
+		compute: func(_ quote.Quote, src [][]float64, n []int) []float64 {
+			return onFinite(src[0], lookback(n[0]), func(in []float64) []float64 { return fn(in, n[0]) })
+		},
+	}
+}
+
+// onFinite считает fn по ряду без начальных NaN (прогрева вложенного индикатора)
+// и маскирует первые lookback значений результата, которые talib заполняет нулями.
+func onFinite(src []float64, lookback int, fn func(in []float64) []float64) []float64 {
+	out := nanSeries(len(src))
+	start := 0
+	for start < len(src) && (math.IsNaN(src[start]) || math.IsInf(src[start], 0)) {
+		start++
+	}
+	if len(src)-start <= lookback {
+		return out
+	}
+	copy(out[start+lookback:], fn(src[start:])[lookback:])
+	return out
+}
+
+func crossing(a, b []float64, crossed func(prevA, prevB, a, b float64) bool) []float64 {
+	out := make([]float64, len(a))
+	for i := 1; i < len(a); i++ {
+		if finite(a[i-1], b[i-1], a[i], b[i]) && crossed(a[i-1], b[i-1], a[i], b[i]) {
+			out[i] = 1
+		}
+	}
+	return out
+}
+
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
+
+func finite(vals ...float64) bool {
+	for _, v := range vals {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
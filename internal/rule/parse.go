@@ -0,0 +1,341 @@
+// Package rule - язык правил входа и выхода, например
+//
+//	cross_up(close, ema(51)) and rsi(14) > 30
+//
+// Выражение разбирается в дерево, проверяется по типам (число или условие)
+// и вычисляется по всем барам котировки сразу.
+package rule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Error - ошибка разбора или проверки типов с позицией в тексте правила.
+type Error struct {
+	Line, Column int
+	Msg          string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Position возвращает строку и столбец ошибки, начиная с 1.
+func (e *Error) Position() (line, column int) {
+	return e.Line, e.Column
+}
+
+func errorAt(src string, pos int, format string, args ...any) *Error {
+	line, col := 1, 1
+	for _, r := range src[:pos] {
+		if r == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return &Error{Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of rule"
+	}
+	return strconv.Quote(t.text)
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokComma, ",", i})
+			i++
+		case c >= '0' && c <= '9' || c == '.':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			if _, err := strconv.ParseFloat(src[start:i], 64); err != nil {
+				return nil, errorAt(src, start, "invalid number %q", src[start:i])
+			}
+			tokens = append(tokens, token{tokNumber, src[start:i], start})
+		case isLetter(src[i]):
+			start := i
+			for i < len(src) && (isLetter(src[i]) || src[i] >= '0' && src[i] <= '9') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, strings.ToLower(src[start:i]), start})
+		default:
+			op := ""
+			for _, candidate := range []string{"<=", ">=", "==", "!=", "<", ">", "+", "-", "*", "/"} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, errorAt(src, i, "unexpected character %q", c)
+			}
+			tokens = append(tokens, token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(src)}), nil
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+type parser struct {
+	src    string
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) take() token {
+	t := p.tokens[p.next]
+	if t.kind != tokEOF {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.take()
+	if t.kind != kind {
+		return t, errorAt(p.src, t.pos, "expected %s, found %s", what, t)
+	}
+	return t, nil
+}
+
+func (p *parser) isWord(word string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && t.text == word
+}
+
+func (p *parser) isOp(ops ...string) bool {
+	t := p.peek()
+	if t.kind != tokOp {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parse разбирает выражение по приоритетам: or < and < not < сравнение < + - < * / < унарный минус.
+func parse(src string) (node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src, tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, errorAt(src, 0, "rule is empty")
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, errorAt(src, t.pos, "unexpected %s after end of expression", t)
+	}
+	return n, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isWord("or") {
+		t := p.take()
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{pos: t.pos, op: "or", x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	x, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isWord("and") {
+		t := p.take()
+		y, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{pos: t.pos, op: "and", x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isWord("not") {
+		t := p.take()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{pos: t.pos, op: "not", x: x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	x, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("<", "<=", ">", ">=", "==", "!=") {
+		t := p.take()
+		y, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{pos: t.pos, op: t.text, x: x, y: y}
+		if p.isOp("<", "<=", ">", ">=", "==", "!=") {
+			return nil, errorAt(p.src, p.peek().pos, "comparisons cannot be chained, use and")
+		}
+	}
+	return x, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	x, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+", "-") {
+		t := p.take()
+		y, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{pos: t.pos, op: t.text, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*", "/") {
+		t := p.take()
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{pos: t.pos, op: t.text, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOp("-") {
+		t := p.take()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{pos: t.pos, op: "-", x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.take()
+	switch t.kind {
+	case tokNumber:
+		v, _ := strconv.ParseFloat(t.text, 64)
+		return &number{pos: t.pos, value: v}, nil
+	case tokLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			return &boolean{pos: t.pos, value: t.text == "true"}, nil
+		case "and", "or", "not":
+			return nil, errorAt(p.src, t.pos, "expected a value, found %s", t)
+		}
+		if p.peek().kind != tokLParen {
+			return &ident{pos: t.pos, name: t.text}, nil
+		}
+		p.take()
+		c := &call{pos: t.pos, name: t.text}
+		if p.peek().kind == tokRParen {
+			p.take()
+			return c, nil
+		}
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			c.args = append(c.args, arg)
+			if p.peek().kind == tokComma {
+				p.take()
+				continue
+			}
+			if _, err := p.expect(tokRParen, "',' or ')'"); err != nil {
+				return nil, err
+			}
+			return c, nil
+		}
+	}
+	return nil, errorAt(p.src, t.pos, "expected a value, found %s", t)
+}
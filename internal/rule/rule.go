@@ -0,0 +1,143 @@
+package rule
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/markcheno/go-quote"
+)
+
+// Rule - разобранное и проверенное правило-условие.
+type Rule struct {
+	src  string
+	root node
+}
+
+// Compile разбирает правило и проверяет типы; результат должен быть условием.
+// Ошибки имеют тип *Error с позицией в src.
+func Compile(src string) (*Rule, error) {
+	root, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	c := checker{src: src}
+	typ, err := c.check(root)
+	if err != nil {
+		return nil, err
+	}
+	if typ != Bool {
+		return nil, errorAt(src, root.position(), "rule must be a condition, got a number (compare it, e.g. %s > 0)", root)
+	}
+	return &Rule{src: src, root: root}, nil
+}
+
+func (r *Rule) String() string {
+	return r.src
+}
+
+// functionUsages возвращает подсказки по встроенным функциям для сообщений об ошибках.
+func functionUsages() []string {
+	usages := make([]string, 0, len(functions))
+	for _, f := range functions {
+		usages = append(usages, f.usage)
+	}
+	sort.Strings(usages)
+	return usages
+}
+
+var fields = map[string]func(q quote.Quote) []float64{
+	"open":   func(q quote.Quote) []float64 { return q.Open },
+	"high":   func(q quote.Quote) []float64 { return q.High },
+	"low":    func(q quote.Quote) []float64 { return q.Low },
+	"close":  func(q quote.Quote) []float64 { return q.Close },
+	"volume": func(q quote.Quote) []float64 { return q.Volume },
+}
+
+type checker struct {
+	src string
+}
+
+func (c checker) errorf(n node, format string, args ...any) error {
+	return errorAt(c.src, n.position(), format, args...)
+}
+
+func (c checker) check(n node) (Type, error) {
+	switch n := n.(type) {
+	case *number:
+		return Number, nil
+	case *boolean:
+		return Bool, nil
+	case *ident:
+		if _, ok := fields[n.name]; !ok {
+			return 0, c.errorf(n, "unknown value %q, available: open, high, low, close, volume", n.name)
+		}
+		return Number, nil
+	case *unary:
+		want := Number
+		if n.op == "not" {
+			want = Bool
+		}
+		if err := c.expect(n.x, want, n.op); err != nil {
+			return 0, err
+		}
+		return want, nil
+	case *binary:
+		operand, result := Number, Number
+		switch n.op {
+		case "and", "or":
+			operand, result = Bool, Bool
+		case "<", "<=", ">", ">=", "==", "!=":
+			result = Bool
+		}
+		if err := c.expect(n.x, operand, n.op); err != nil {
+			return 0, err
+		}
+		if err := c.expect(n.y, operand, n.op); err != nil {
+			return 0, err
+		}
+		return result, nil
+	case *call:
+		return c.checkCall(n)
+	}
+	return 0, c.errorf(n, "unsupported expression")
+}
+
+func (c checker) expect(n node, want Type, op string) error {
+	got, err := c.check(n)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return c.errorf(n, "%s expects a %s, got a %s", op, want, got)
+	}
+	return nil
+}
+
+func (c checker) checkCall(n *call) (Type, error) {
+	f, ok := functions[n.name]
+	if !ok {
+		return 0, c.errorf(n, "unknown function %q, available: %s", n.name, strings.Join(functionUsages(), ", "))
+	}
+	sources := len(n.args) - f.lengths
+	if sources != f.sources && !(f.optSource && sources == f.sources-1) {
+		return 0, c.errorf(n, "wrong number of arguments for %s, usage: %s", n.name, f.usage)
+	}
+	if sources < f.sources {
+		// ema(51) и ema(close, 51) - одно выражение с одним ключом кэша
+		n.args = append([]node{&ident{pos: n.pos, name: "close"}}, n.args...)
+		sources++
+	}
+	for _, arg := range n.args[:sources] {
+		if err := c.expect(arg, Number, n.name); err != nil {
+			return 0, err
+		}
+	}
+	for _, arg := range n.args[sources:] {
+		num, ok := arg.(*number)
+		if !ok || num.value != math.Trunc(num.value) || num.value < float64(f.minLength) || num.value > maxLength {
+			return 0, c.errorf(arg, "length in %s must be an integer constant from %d to %d, got %s", n.name, f.minLength, maxLength, arg)
+		}
+	}
+	return f.result, nil
+}
@@ -0,0 +1,127 @@
+package rule
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src          string
+		line, column int
+		msg          string
+	}{
+		{"", 1, 1, "rule is empty"},
+		{"close > é", 1, 9, "unexpected character 'é'"},
+		{"close > 1 and # 2", 1, 15, "unexpected character '#'"},
+		{"close > 1..2", 1, 9, `invalid number "1..2"`},
+		{"close >", 1, 8, "expected a value, found end of rule"},
+		{"(close > 1", 1, 11, "expected ')', found end of rule"},
+		{"close > 1 2", 1, 11, `unexpected "2" after end of expression`},
+		{"1 < close < 2", 1, 11, "comparisons cannot be chained"},
+		{"close > 1 and\n  price > 2", 2, 3, `unknown value "price"`},
+		{"foo(close) > 1", 1, 1, `unknown function "foo"`},
+		{"ema(close, 3, 4) > 1", 1, 1, "wrong number of arguments for ema, usage: ema([source,] length)"},
+		{"ema(close) > 1", 1, 5, "length in ema must be an integer constant from 2 to 1000000, got close"},
+		{"ema(close, 1.5) > 1", 1, 12, "length in ema must be an integer constant from 2 to 1000000"},
+		{"ema(close, 1) > 1", 1, 12, "length in ema must be an integer constant from 2 to 1000000"},
+		{"prev(close, 100000000000000000000) > 1", 1, 13, "length in prev must be an integer constant from 1 to 1000000"},
+		{"atr(100000000000000000000) > 1", 1, 5, "length in atr must be an integer constant from 1 to 1000000"},
+		{"close and volume", 1, 1, "and expects a condition, got a number"},
+		{"not close", 1, 5, "not expects a condition, got a number"},
+		// позиция бинарного выражения - его оператор
+		{"rsi(14) + (close > 1) > 2", 1, 18, "+ expects a number, got a condition"},
+		{"ema(51)", 1, 1, "rule must be a condition, got a number (compare it, e.g. ema(close, 51) > 0)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Compile(tt.src)
+			var ruleErr *Error
+			if !errors.As(err, &ruleErr) {
+				t.Fatalf("Compile(%q) error = %v, want *Error", tt.src, err)
+			}
+			if line, column := ruleErr.Position(); line != tt.line || column != tt.column {
+				t.Errorf("position = %d:%d, want %d:%d (%s)", line, column, tt.line, tt.column, ruleErr.Msg)
+			}
+			if !strings.Contains(ruleErr.Msg, tt.msg) {
+				t.Errorf("message = %q, want it to contain %q", ruleErr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+// testQuote строит котировку по ценам закрытия; high и low отстоят от close на 1.
+func testQuote(closes ...float64) quote.Quote {
+	q := quote.NewQuote("TEST", len(closes))
+	for i, c := range closes {
+		q.Date[i] = time.Date(2024, 1, 1, i, 0, 0, 0, time.UTC)
+		q.Open[i], q.High[i], q.Low[i], q.Close[i], q.Volume[i] = c, c+1, c-1, c, float64(10*(i+1))
+	}
+	return q
+}
+
+func TestEval(t *testing.T) {
+	q := testQuote(1, 2, 3, 4, 3, 2, 5)
+	tests := []struct {
+		src  string
+		want []bool
+	}{
+		{"close > 2", []bool{false, false, true, true, true, false, true}},
+		{"close > 2 and not close == 4", []bool{false, false, true, false, true, false, true}},
+		{"close < 2 or close >= 5", []bool{true, false, false, false, false, false, true}},
+		// sma(3): NaN NaN 2 3 3.333 3 3.333 - сравнение с прогревом ложно
+		{"close > sma(3)", []bool{false, false, true, true, false, false, true}},
+		{"cross_up(close, 2.5)", []bool{false, false, true, false, false, false, true}},
+		{"cross_down(close, sma(3))", []bool{false, false, false, false, true, false, false}},
+		{"close - prev(close, 1) > 0", []bool{false, true, true, true, false, false, true}},
+		{"abs(close - prev(close, 2)) >= 2", []bool{false, false, true, true, false, true, true}},
+		{"high - low == 2 * 1", []bool{true, true, true, true, true, true, true}},
+		{"volume / 10 > -close + 6", []bool{false, false, false, true, true, true, true}},
+		{"highest(3) == close", []bool{false, false, true, true, false, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			r, err := Compile(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := NewEvaluator(q).Eval(r); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndicatorsShareDefaultSource(t *testing.T) {
+	q := testQuote(1, 2, 3, 4, 5)
+	e := NewEvaluator(q)
+	for _, src := range []string{"close > ema(3)", "cross_up(close, ema(close, 3))"} {
+		r, err := Compile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Eval(r)
+	}
+
+	indicators := e.Indicators()
+	if len(indicators) != 1 {
+		t.Fatalf("indicators = %v, want only ema(close, 3)", indicators)
+	}
+	ema, ok := indicators["ema(close, 3)"]
+	if !ok {
+		t.Fatalf("no ema(close, 3) in %v", indicators)
+	}
+	// ema(3) начинается с sma первых трёх баров, далее alpha = 0.5
+	want := []float64{math.NaN(), math.NaN(), 2, 3, 4}
+	for i := range want {
+		if math.IsNaN(want[i]) != math.IsNaN(ema[i]) || !math.IsNaN(want[i]) && math.Abs(ema[i]-want[i]) > 1e-9 {
+			t.Fatalf("ema = %v, want %v", ema, want)
+		}
+	}
+}
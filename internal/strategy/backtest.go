@@ -0,0 +1,442 @@
+package strategy
+
+import (
+	"fmt"
+	"main/internal/model"
+	"math"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+type OptimizationResult struct {
+	Config          any                `json:"-"`
+	InitialCapital  float64            `json:"initialCapital"`
+	FinalEquity     float64            `json:"finalEquity"`   // капитал на последнем баре с учётом открытых позиций
+	Profit          float64            `json:"profit"`        // прибыль в валюте счёта за вычетом издержек
+	ReturnPercent   float64            `json:"returnPercent"` // прибыль в % от начального капитала
+	GrossProfit     float64            `json:"grossProfit"`   // прибыль по ценам сигналов без издержек
+	Costs           float64            `json:"costs"`         // комиссии, спред и проскальзывание
+	Trades          int                `json:"trades"`
+	WinRate         float64            `json:"winRate"`
+	Drawdown        float64            `json:"drawdown"`        // максимальная просадка капитала в валюте счёта
+	DrawdownPercent float64            `json:"drawdownPercent"` // максимальная просадка в % от пика капитала
+	WinRatePercent  float64            `json:"winRatePercent"`
+	CountSignalBuy  int                `json:"countSignalBuy"`
+	CountSignalSell int                `json:"countSignalSell"`
+	Long            SideStats          `json:"long"`
+	Short           SideStats          `json:"short"`
+	ExitReasons     map[ExitReason]int `json:"exitReasons"` // число сделок по причинам закрытия
+	Metrics         Metrics            `json:"metrics"`
+	Objective       Objective          `json:"objective"` // показатель оптимизации
+	Score           float64            `json:"score"`     // значение Objective
+	EquityCurve     []float64          `json:"-"`         // капитал на закрытии каждого бара
+	Ledger          []Trade            `json:"-"`         // закрытые сделки по порядку закрытия
+
+	rank float64 // значение Objective для сравнения вариантов, см. Objective.rank
+}
+
+// Trade - закрытая сделка бэктеста.
+type Trade struct {
+	Side          string     `json:"side"` // long или short
+	EntryTime     time.Time  `json:"entryTime"`
+	ExitTime      time.Time  `json:"exitTime"`
+	EntryPrice    float64    `json:"entryPrice"` // цены исполнения с учётом спреда и проскальзывания
+	ExitPrice     float64    `json:"exitPrice"`
+	Quantity      float64    `json:"quantity"`
+	Profit        float64    `json:"profit"`        // за вычетом издержек
+	ProfitPercent float64    `json:"profitPercent"` // в % от стоимости позиции на входе
+	Costs         float64    `json:"costs"`
+	Bars          int        `json:"bars"`       // баров от входа до выхода
+	MAEPercent    float64    `json:"maePercent"` // худшее движение цены против позиции, % от входа
+	MFEPercent    float64    `json:"mfePercent"` // лучшее движение цены в пользу позиции, % от входа
+	ExitReason    ExitReason `json:"exitReason"`
+}
+
+// SideStats - итоги сделок одного направления.
+type SideStats struct {
+	Profit  float64 `json:"profit"`
+	Trades  int     `json:"trades"`
+	Wins    int     `json:"wins"`
+	WinRate float64 `json:"winRate"`
+}
+
+func (o OptimizationResult) String() string {
+	return fmt.Sprintf(
+		"=== Optimization Result ===\n"+
+			"Config: %+v\n"+
+			"Profit: %.2f\n"+
+			"Trades: %d\n"+
+			"WinRate: %.2f%%\n"+
+			"Drawdown: %.2f\n",
+		o.Config, o.Profit, o.Trades, o.WinRate*100, o.Drawdown,
+	)
+}
+
+// Mode - направления сделок в бэктесте.
+type Mode string
+
+const (
+	ModeLong  Mode = "long"  // покупка открывает длинную позицию, продажа закрывает
+	ModeShort Mode = "short" // продажа открывает короткую позицию, покупка закрывает
+	ModeBoth  Mode = "both"  // разворот: сигнал закрывает позиции против него и открывает по нему
+)
+
+// BacktestConfig - настройки бэктеста в конфигурации стратегии.
+type BacktestConfig struct {
+	Mode   Mode         `yaml:"mode" json:"mode"` // long, short или both; пусто - long
+	Costs  CostConfig   `yaml:"costs" json:"costs"`
+	Sizing SizingConfig `yaml:"sizing" json:"sizing"`
+	Exits  ExitConfig   `yaml:"exits" json:"exits"`
+
+	Objective Objective `yaml:"objective" json:"objective"` // что максимизирует оптимизатор; пусто - profit
+}
+
+// DefaultBacktestConfig - только длинные позиции по одной единице, без издержек и защитных выходов.
+func DefaultBacktestConfig() BacktestConfig {
+	return BacktestConfig{
+		Mode:      ModeLong,
+		Objective: ObjectiveProfit,
+		Costs:     CostConfig{ATRLength: 14},
+		Exits:     ExitConfig{ATRLength: 14, IntrabarPriority: PriorityStop},
+		Sizing: SizingConfig{
+			InitialCapital: 10000,
+			Policy:         SizeQuantity,
+			Quantity:       1,
+			EquityPercent:  10,
+			RiskPercent:    1,
+			ATRLength:      14,
+			ATRMultiple:    2,
+			KellyFraction:  0.5,
+			Compounding:    true,
+		},
+	}
+}
+
+func (c BacktestConfig) Validate() error {
+	switch c.Mode {
+	case "", ModeLong, ModeShort, ModeBoth:
+	default:
+		return fmt.Errorf("backtest.mode must be long, short or both, got %q", c.Mode)
+	}
+	if err := c.Costs.Validate(); err != nil {
+		return err
+	}
+	if err := c.Sizing.Validate(); err != nil {
+		return err
+	}
+	if err := c.Objective.Validate(); err != nil {
+		return err
+	}
+	return c.Exits.Validate()
+}
+
+// Backtester - необязательный интерфейс конфигурации (Settings) с настройками бэктеста.
+// Для остальных стратегий бэктест открывает только длинные позиции.
+type Backtester interface {
+	BacktestConfig() BacktestConfig
+}
+
+func backtestConfig(s Strategy) BacktestConfig {
+	if b, ok := s.Settings().(Backtester); ok {
+		return b.BacktestConfig()
+	}
+	return DefaultBacktestConfig()
+}
+
+// Evaluate прогоняет бэктест стратегии с её текущей конфигурацией.
+func Evaluate(s Strategy, candles quote.Quote) OptimizationResult {
+	result := backtest(s, candles, true, false)
+	result.Config = s.Settings()
+	return result
+}
+
+// Optimize перебирает варианты конфигурации base и возвращает лучший по Objective
+// из настроек бэктеста вместе с экземпляром стратегии, настроенным на эту конфигурацию.
+func Optimize(base Strategy, candles quote.Quote) (OptimizationResult, Strategy) {
+	best := OptimizationResult{rank: math.Inf(-1)}
+	var bestStrategy Strategy
+
+	base.Variants(func(strat Strategy) bool {
+		result := backtest(strat, candles, false, false)
+
+		// из вариантов с равным значением цели лучше более прибыльный
+		if result.rank > best.rank || result.rank == best.rank && result.Profit > best.Profit {
+			best = result
+			best.Config = strat.Settings()
+			bestStrategy = strat
+		}
+		return true
+	})
+
+	return best, bestStrategy
+}
+
+// position - открытая позиция; side 1 для длинной и -1 для короткой.
+type position struct {
+	side       float64
+	qty        float64
+	entryPrice float64 // цена сигнала
+	fillPrice  float64 // цена исполнения с учётом спреда и проскальзывания
+	entryFee   float64
+	entryTime  time.Time
+	entryIndex int
+
+	// защитные уровни, см. exitModel; 0 - уровень не выставлен
+	stop, target float64
+	trailATR     float64 // отступ скользящего стопа по ATR на входе
+	best         float64 // лучшая цена с момента входа до предыдущего бара
+
+	low, high float64 // крайние цены с момента входа для MAE/MFE
+}
+
+func (p *position) track(high, low float64) {
+	p.high = math.Max(p.high, high)
+	p.low = math.Min(p.low, low)
+}
+
+// excursions возвращает MAE и MFE в % от цены входа.
+func (p *position) excursions() (mae, mfe float64) {
+	down := (p.fillPrice - p.low) / p.fillPrice * 100
+	up := (p.high - p.fillPrice) / p.fillPrice * 100
+	if p.side < 0 {
+		down, up = up, down
+	}
+	return math.Max(down, 0), math.Max(up, 0)
+}
+
+// pnl - результат позиции при закрытии по price без издержек выхода.
+func (p *position) pnl(price float64) float64 {
+	return p.qty*p.side*(price-p.fillPrice) - p.entryFee
+}
+
+// backtest прогоняет сигналы стратегии по candles: сигнал закрывает все позиции
+// против себя и, если Mode и лимит позиций разрешают, открывает позицию по себе
+// размером по Sizing от текущего капитала. Сделки записываются в Ledger,
+// verbose передаётся в Execute стратегии.
+func backtest(s Strategy, candles quote.Quote, verbose bool, closeAllAtEnd bool) (result OptimizationResult) {
+	var positions []*position
+	var currentEquity float64 = 0.0
+
+	cfg := backtestConfig(s)
+	mode := cfg.Mode
+	costs := newCostModel(cfg.Costs, candles)
+	exits := newExitModel(cfg.Exits, candles)
+	sizes := newSizer(cfg.Sizing, candles)
+	capital := cfg.Sizing.InitialCapital
+	peak := capital
+	exposedBars := 0
+	openLong := mode != ModeShort
+	openShort := mode == ModeShort || mode == ModeBoth
+
+	// Пересчитаем сигналы по стратегии
+	s.Execute(candles, verbose)
+
+	// build fast lookup maps
+	buyMap := buildSignalMap(s.BuySignals())
+	sellMap := buildSignalMap(s.SellSignals())
+
+	result.EquityCurve = make([]float64, len(candles.Close))
+	result.ExitReasons = make(map[ExitReason]int)
+	result.Ledger = make([]Trade, 0)
+
+	// closePosition закрывает позицию по price на баре i и записывает сделку
+	closePosition := func(pos *position, i int, price float64, reason ExitReason, maker bool) {
+		side := pos.side
+		exitPrice, exitFee := costs.fill(-side, i, price, pos.qty, maker)
+		pnl := pos.pnl(exitPrice) - exitFee
+		gross := pos.qty * side * (price - pos.entryPrice)
+		currentEquity += pnl
+		sizes.record(pnl)
+		result.GrossProfit += gross
+		result.Costs += gross - pnl
+		result.ExitReasons[reason]++
+
+		mae, mfe := pos.excursions()
+		trade := Trade{
+			Side:          "long",
+			EntryTime:     pos.entryTime,
+			ExitTime:      candles.Date[i],
+			EntryPrice:    pos.fillPrice,
+			ExitPrice:     exitPrice,
+			Quantity:      pos.qty,
+			Profit:        pnl,
+			ProfitPercent: pnl / (pos.qty * pos.fillPrice) * 100,
+			Costs:         gross - pnl,
+			Bars:          i - pos.entryIndex,
+			MAEPercent:    mae,
+			MFEPercent:    mfe,
+			ExitReason:    reason,
+		}
+		stats := &result.Long
+		if side < 0 {
+			trade.Side = "short"
+			stats = &result.Short
+		}
+		result.Ledger = append(result.Ledger, trade)
+		stats.Trades++
+		stats.Profit += pnl
+
+		if pnl > 0 {
+			stats.Wins++
+		}
+	}
+
+	// closeSide закрывает позиции направления side по price на баре i
+	closeSide := func(side float64, i int, price float64, reason ExitReason) {
+		kept := positions[:0]
+		for _, pos := range positions {
+			if pos.side != side {
+				kept = append(kept, pos)
+				continue
+			}
+			closePosition(pos, i, price, reason, false)
+		}
+		positions = kept
+	}
+
+	// protect закрывает позиции, открытые до бара i, по стопам, целям и времени в сделке
+	protect := func(i int) {
+		kept := positions[:0]
+		for _, pos := range positions {
+			if pos.entryIndex >= i {
+				kept = append(kept, pos)
+				continue
+			}
+			price, reason, maker, ok := exits.check(pos, i)
+			if !ok {
+				pos.track(candles.High[i], candles.Low[i])
+				kept = append(kept, pos)
+				continue
+			}
+			// после выхода по уровню остаток бара в сделку не входит
+			pos.track(price, price)
+			closePosition(pos, i, price, reason, maker)
+		}
+		positions = kept
+	}
+
+	// equityAt - капитал с учётом открытых позиций по price
+	equityAt := func(price float64) float64 {
+		equity := capital + currentEquity
+		for _, pos := range positions {
+			equity += pos.pnl(price)
+		}
+		return equity
+	}
+
+	open := func(side float64, i int, price float64) {
+		if price <= 0 || cfg.Sizing.MaxOpenPositions > 0 && len(positions) >= cfg.Sizing.MaxOpenPositions {
+			return
+		}
+		equity := equityAt(price)
+		qty := sizes.quantity(i, price, equity)
+		// все позиции вместе не дороже leverage капиталов
+		if leverage := cfg.Sizing.leverage(); !math.IsInf(leverage, 1) {
+			free := leverage * equity
+			for _, pos := range positions {
+				free -= pos.qty * price
+			}
+			qty = math.Min(qty, free/price)
+		}
+		if qty <= 0 {
+			return
+		}
+		fillPrice, fee := costs.fill(side, i, price, qty, false)
+		pos := &position{
+			side:       side,
+			qty:        qty,
+			entryPrice: price,
+			fillPrice:  fillPrice,
+			entryFee:   fee,
+			entryTime:  candles.Date[i],
+			entryIndex: i,
+			low:        fillPrice,
+			high:       fillPrice,
+		}
+		exits.arm(pos, i, price)
+		positions = append(positions, pos)
+	}
+
+	for i, price := range candles.Close {
+		tKey := candles.Date[i].UnixNano()
+
+		protect(i)
+
+		if buyMap[tKey] {
+			closeSide(-1, i, price, ExitSignal)
+			if openLong {
+				open(1, i, price)
+			}
+		}
+
+		if sellMap[tKey] {
+			closeSide(1, i, price, ExitSignal)
+			if openShort {
+				open(-1, i, price)
+			}
+		}
+
+		result.EquityCurve[i] = equityAt(price)
+
+		// пик отсчитывается от начального капитала
+		if result.EquityCurve[i] > peak {
+			peak = result.EquityCurve[i]
+		}
+		if dd := peak - result.EquityCurve[i]; dd > result.Drawdown {
+			result.Drawdown = dd
+		}
+		if len(positions) > 0 {
+			exposedBars++
+		}
+	}
+
+	// close remaining positions optionally
+	if closeAllAtEnd && len(positions) > 0 {
+		last := len(candles.Close) - 1
+
+		closeSide(1, last, candles.Close[last], ExitEndOfData)
+		closeSide(-1, last, candles.Close[last], ExitEndOfData)
+		result.EquityCurve[len(result.EquityCurve)-1] = capital + currentEquity
+	}
+
+	result.InitialCapital = capital
+	result.Profit = currentEquity
+	result.ReturnPercent = currentEquity / capital * 100
+	result.FinalEquity = capital + currentEquity
+	if n := len(result.EquityCurve); n > 0 {
+		result.FinalEquity = result.EquityCurve[n-1]
+	}
+	result.Trades = result.Long.Trades + result.Short.Trades
+	wins := result.Long.Wins + result.Short.Wins
+	if result.Trades > 0 {
+		result.WinRate = float64(wins) / float64(result.Trades)
+	}
+	result.WinRatePercent = result.WinRate * 100
+	for _, stats := range []*SideStats{&result.Long, &result.Short} {
+		if stats.Trades > 0 {
+			stats.WinRate = float64(stats.Wins) / float64(stats.Trades)
+		}
+	}
+	result.CountSignalBuy = len(s.BuySignals())
+	result.CountSignalSell = len(s.SellSignals())
+
+	result.Metrics = computeMetrics(candles, capital, result.EquityCurve, result.Ledger, exposedBars, result.Drawdown)
+	result.DrawdownPercent = result.Metrics.MaxDrawdownPercent
+	result.Objective = cfg.Objective
+	if result.Objective == "" {
+		result.Objective = ObjectiveProfit
+	}
+	result.Score = result.Objective.score(result)
+	result.rank = result.Objective.rank(result)
+
+	return result
+}
+
+func buildSignalMap(data []model.IndicatorData) map[int64]bool {
+	m := make(map[int64]bool, len(data))
+	for _, d := range data {
+		m[d.Date.UnixNano()] = true
+	}
+	return m
+}
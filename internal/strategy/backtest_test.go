@@ -0,0 +1,253 @@
+package strategy
+
+import (
+	"main/internal/model"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// signalStrategy подаёт сигналы на заданных барах и отдаёт настройки бэктеста.
+type signalStrategy struct {
+	cfg         BacktestConfig
+	buys, sells []int
+
+	candles quote.Quote
+
+	// variants - варианты для Optimize, пусто - только сама стратегия
+	variants []*signalStrategy
+}
+
+func (s *signalStrategy) Settings() any                   { return s }
+func (s *signalStrategy) BacktestConfig() BacktestConfig  { return s.cfg }
+func (s *signalStrategy) Series() map[string]model.Series { return nil }
+func (s *signalStrategy) Levels() map[string]float64      { return nil }
+
+func (s *signalStrategy) Variants(yield func(Strategy) bool) {
+	if len(s.variants) == 0 {
+		yield(s)
+		return
+	}
+	for _, v := range s.variants {
+		if !yield(v) {
+			return
+		}
+	}
+}
+
+func (s *signalStrategy) Execute(candles quote.Quote, _ bool) (bool, bool) {
+	s.candles = candles
+	return false, false
+}
+
+func (s *signalStrategy) BuySignals() []model.IndicatorData  { return s.signals(s.buys) }
+func (s *signalStrategy) SellSignals() []model.IndicatorData { return s.signals(s.sells) }
+
+func (s *signalStrategy) signals(bars []int) []model.IndicatorData {
+	out := make([]model.IndicatorData, 0, len(bars))
+	for _, i := range bars {
+		out = append(out, model.IndicatorData{Date: s.candles.Date[i], Value: s.candles.Close[i]})
+	}
+	return out
+}
+
+// ohlc - бар теста: open, high, low, close.
+type ohlc [4]float64
+
+// testQuote строит часовые бары; бар из одной цены - open = high = low = close.
+func testQuote(bars ...ohlc) quote.Quote {
+	q := quote.NewQuote("TEST", 0)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, b := range bars {
+		q.Date = append(q.Date, start.Add(time.Duration(i)*time.Hour))
+		q.Open = append(q.Open, b[0])
+		q.High = append(q.High, b[1])
+		q.Low = append(q.Low, b[2])
+		q.Close = append(q.Close, b[3])
+		q.Volume = append(q.Volume, 1)
+	}
+	return q
+}
+
+func flat(closes ...float64) quote.Quote {
+	bars := make([]ohlc, len(closes))
+	for i, c := range closes {
+		bars[i] = ohlc{c, c, c, c}
+	}
+	return testQuote(bars...)
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestLeverageCap(t *testing.T) {
+	percent := DefaultBacktestConfig()
+	percent.Sizing.Policy = SizePercent
+	percent.Sizing.EquityPercent = 50
+
+	capped := DefaultBacktestConfig()
+	capped.Sizing.MaxLeverage = 0.5
+
+	tests := []struct {
+		name   string
+		cfg    BacktestConfig
+		prices []float64
+		buys   []int     // продажа на последнем баре закрывает все позиции
+		qty    []float64 // количества сделок по порядку
+	}{
+		// прежний бэктест: по одной единице на каждый сигнал, капитал не ограничивает
+		{"default quantity is uncapped", DefaultBacktestConfig(), []float64{20000, 20000, 20000}, []int{0, 1}, []float64{1, 1}},
+		// 50% капитала на сделку, третья покупка не помещается в один капитал
+		{"percent defaults to one capital", percent, []float64{100, 100, 100, 100}, []int{0, 1, 2}, []float64{50, 50}},
+		// явный предел действует и на фиксированное количество
+		{"explicit limit caps quantity", capped, []float64{20000, 20000}, []int{0}, []float64{0.25}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &signalStrategy{cfg: tt.cfg, buys: tt.buys, sells: []int{len(tt.prices) - 1}}
+			result := Evaluate(s, flat(tt.prices...))
+			if len(result.Ledger) != len(tt.qty) {
+				t.Fatalf("got %d trades, want %d: %+v", len(result.Ledger), len(tt.qty), result.Ledger)
+			}
+			for i, trade := range result.Ledger {
+				if !near(trade.Quantity, tt.qty[i]) {
+					t.Errorf("trade %d quantity = %v, want %v", i, trade.Quantity, tt.qty[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCosts(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      CostConfig
+		entry, exit float64 // цены исполнения
+		profit      float64
+		costs       float64
+	}{
+		{"none", CostConfig{}, 100, 110, 10, 0},
+		// проскальзывание 10 б.п. против сделки, комиссия 0.1% от цены исполнения
+		{"taker fee and slippage", CostConfig{TakerFeePercent: 0.1, SlippageBps: 10}, 100.1, 109.89,
+			(109.89 - 100.1) - 0.1001 - 0.10989, 10 - ((109.89 - 100.1) - 0.1001 - 0.10989)},
+		// исполнение платит половину спреда
+		{"spread", CostConfig{SpreadBps: 20}, 100.1, 109.89, 9.79, 0.21},
+		{"fixed fee", CostConfig{FixedFee: 1}, 100, 110, 8, 2},
+		// выход по сигналу - рыночная заявка, комиссия maker не применяется
+		{"maker fee ignored on signal exit", CostConfig{MakerFeePercent: 1}, 100, 110, 10, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultBacktestConfig()
+			cfg.Costs = tt.config
+			s := &signalStrategy{cfg: cfg, buys: []int{0}, sells: []int{1}}
+			result := Evaluate(s, flat(100, 110))
+			if len(result.Ledger) != 1 {
+				t.Fatalf("got %d trades, want 1", len(result.Ledger))
+			}
+			trade := result.Ledger[0]
+			if !near(trade.EntryPrice, tt.entry) || !near(trade.ExitPrice, tt.exit) {
+				t.Errorf("fills = %v -> %v, want %v -> %v", trade.EntryPrice, trade.ExitPrice, tt.entry, tt.exit)
+			}
+			if !near(trade.Profit, tt.profit) || !near(trade.Costs, tt.costs) {
+				t.Errorf("profit = %v, costs = %v, want %v, %v", trade.Profit, trade.Costs, tt.profit, tt.costs)
+			}
+			if !near(result.GrossProfit, 10) || !near(result.Costs, tt.costs) {
+				t.Errorf("gross = %v, result costs = %v", result.GrossProfit, result.Costs)
+			}
+		})
+	}
+}
+
+func TestExits(t *testing.T) {
+	fees := CostConfig{MakerFeePercent: 0, TakerFeePercent: 0.1}
+	tests := []struct {
+		name     string
+		exits    ExitConfig
+		bar      ohlc // бар после входа по 100
+		reason   ExitReason
+		exit     float64
+		exitFees float64
+	}{
+		// цель исполняется лимитной заявкой по maker, стоп - рыночной по taker
+		{"take profit pays maker fee", ExitConfig{TakeProfitPercent: 5}, ohlc{100, 106, 100, 104}, ExitTakeProfit, 105, 0},
+		{"stop loss pays taker fee", ExitConfig{StopLossPercent: 5}, ohlc{100, 100, 94, 96}, ExitStopLoss, 95, 0.095},
+		{"gap through stop fills at open", ExitConfig{StopLossPercent: 5}, ohlc{90, 92, 89, 91}, ExitStopLoss, 90, 0.09},
+		{"gap through target fills at open", ExitConfig{TakeProfitPercent: 5}, ohlc{108, 109, 107, 108}, ExitTakeProfit, 108, 0},
+		// бар задел и стоп 95, и цель 105
+		{"both hit, stop first", ExitConfig{StopLossPercent: 5, TakeProfitPercent: 5, IntrabarPriority: PriorityStop},
+			ohlc{101, 106, 94, 100}, ExitStopLoss, 95, 0.095},
+		{"both hit, target first", ExitConfig{StopLossPercent: 5, TakeProfitPercent: 5, IntrabarPriority: PriorityTarget},
+			ohlc{101, 106, 94, 100}, ExitTakeProfit, 105, 0},
+		{"both hit, open nearer target", ExitConfig{StopLossPercent: 5, TakeProfitPercent: 5, IntrabarPriority: PriorityOpen},
+			ohlc{103, 106, 94, 100}, ExitTakeProfit, 105, 0},
+		{"both hit, open nearer stop", ExitConfig{StopLossPercent: 5, TakeProfitPercent: 5, IntrabarPriority: PriorityOpen},
+			ohlc{97, 106, 94, 100}, ExitStopLoss, 95, 0.095},
+		{"trailing stop", ExitConfig{TrailingPercent: 10}, ohlc{100, 100, 89, 95}, ExitTrailingStop, 90, 0.09},
+		{"time exit at close", ExitConfig{MaxBars: 1}, ohlc{100, 103, 99, 102}, ExitTime, 102, 0.102},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultBacktestConfig()
+			cfg.Costs = fees
+			cfg.Exits = tt.exits
+			s := &signalStrategy{cfg: cfg, buys: []int{0}}
+			result := Evaluate(s, testQuote(ohlc{100, 100, 100, 100}, tt.bar))
+			if len(result.Ledger) != 1 {
+				t.Fatalf("got %d trades, want 1", len(result.Ledger))
+			}
+			trade := result.Ledger[0]
+			if trade.ExitReason != tt.reason || !near(trade.ExitPrice, tt.exit) {
+				t.Errorf("exit = %s at %v, want %s at %v", trade.ExitReason, trade.ExitPrice, tt.reason, tt.exit)
+			}
+			// вход по рынку: комиссия 0.1 с цены 100
+			if want := tt.exit - 100 - 0.1 - tt.exitFees; !near(trade.Profit, want) {
+				t.Errorf("profit = %v, want %v", trade.Profit, want)
+			}
+		})
+	}
+}
+
+func TestModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        Mode
+		prices      []float64
+		buys, sells []int
+		sides       []string
+		profits     []float64
+	}{
+		{"long", ModeLong, []float64{100, 110}, []int{0}, []int{1}, []string{"long"}, []float64{10}},
+		// продажа открывает короткую позицию, покупка по меньшей цене приносит прибыль
+		{"short", ModeShort, []float64{100, 90}, []int{1}, []int{0}, []string{"short"}, []float64{10}},
+		{"short loses on rise", ModeShort, []float64{100, 120}, []int{1}, []int{0}, []string{"short"}, []float64{-20}},
+		// разворот: продажа закрывает long и открывает short, покупка - наоборот
+		{"both", ModeBoth, []float64{100, 110, 95}, []int{0, 2}, []int{1}, []string{"long", "short"}, []float64{10, 15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultBacktestConfig()
+			cfg.Mode = tt.mode
+			s := &signalStrategy{cfg: cfg, buys: tt.buys, sells: tt.sells}
+			result := Evaluate(s, flat(tt.prices...))
+			if len(result.Ledger) != len(tt.sides) {
+				t.Fatalf("got %d trades, want %d: %+v", len(result.Ledger), len(tt.sides), result.Ledger)
+			}
+			var short float64
+			for i, trade := range result.Ledger {
+				if trade.Side != tt.sides[i] || !near(trade.Profit, tt.profits[i]) {
+					t.Errorf("trade %d = %s %v, want %s %v", i, trade.Side, trade.Profit, tt.sides[i], tt.profits[i])
+				}
+				if trade.Side == "short" {
+					short += trade.Profit
+				}
+			}
+			if !near(result.Short.Profit, short) {
+				t.Errorf("short stats profit = %v, want %v", result.Short.Profit, short)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package strategy
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigStore хранит конфигурацию стратегии в YAML: Primary - пользовательский файл,
+// Fallback - значения по умолчанию, если пользовательского файла нет.
+type ConfigStore struct {
+	Primary  string
+	Fallback string
+}
+
+// Load читает конфигурацию в dst.
+func (s ConfigStore) Load(dst any) error {
+	fileData, err := os.ReadFile(s.Primary)
+	if err != nil {
+		fileData, err = os.ReadFile(s.Fallback)
+		if err != nil {
+			return fmt.Errorf("failed to read both %s and %s: %w", s.Primary, s.Fallback, err)
+		}
+	}
+
+	if err := yaml.Unmarshal(fileData, dst); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return nil
+}
+
+// Save записывает конфигурацию в Primary.
+func (s ConfigStore) Save(src any) error {
+	data, err := yaml.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	err = os.WriteFile(s.Primary, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/markcheno/go-quote"
+	"github.com/markcheno/go-talib"
+)
+
+// CostConfig - издержки, применяемые к каждому исполнению в бэктесте.
+// Спред и проскальзывание ухудшают цену исполнения, комиссии вычитаются из PnL.
+type CostConfig struct {
+	MakerFeePercent float64 `yaml:"maker_fee_percent" json:"makerFeePercent"` // комиссия лимитной заявки (только выход по take-profit), % от цены исполнения
+	TakerFeePercent float64 `yaml:"taker_fee_percent" json:"takerFeePercent"` // комиссия рыночной заявки, % от цены исполнения
+	FixedFee        float64 `yaml:"fixed_fee" json:"fixedFee"`                // фиксированная комиссия за исполнение
+
+	SpreadBps     float64 `yaml:"spread_bps" json:"spreadBps"`         // полный спред в б.п., исполнение платит половину
+	SlippageBps   float64 `yaml:"slippage_bps" json:"slippageBps"`     // проскальзывание в б.п. от цены
+	SlippageATR   float64 `yaml:"slippage_atr" json:"slippageAtr"`     // проскальзывание в долях ATR
+	SlippageRange float64 `yaml:"slippage_range" json:"slippageRange"` // проскальзывание в долях диапазона бара high-low
+	ATRLength     int     `yaml:"atr_length" json:"atrLength"`         // длина ATR для slippage_atr
+}
+
+func (c CostConfig) Validate() error {
+	for _, v := range []struct {
+		name  string
+		value float64
+	}{
+		{"makerFeePercent", c.MakerFeePercent},
+		{"takerFeePercent", c.TakerFeePercent},
+		{"fixedFee", c.FixedFee},
+		{"spreadBps", c.SpreadBps},
+		{"slippageBps", c.SlippageBps},
+		{"slippageAtr", c.SlippageATR},
+		{"slippageRange", c.SlippageRange},
+	} {
+		if v.value < 0 || math.IsNaN(v.value) {
+			return fmt.Errorf("backtest.costs.%s must not be negative, got %v", v.name, v.value)
+		}
+	}
+	if c.SlippageATR > 0 && c.ATRLength < 1 {
+		return fmt.Errorf("backtest.costs.atrLength must be at least 1 with slippageAtr, got %d", c.ATRLength)
+	}
+	return nil
+}
+
+// costModel считает цены исполнения и комиссии по барам котировки.
+type costModel struct {
+	CostConfig
+	candles quote.Quote
+	atr     []float64
+}
+
+func newCostModel(c CostConfig, candles quote.Quote) *costModel {
+	m := &costModel{CostConfig: c, candles: candles}
+	if c.SlippageATR > 0 && len(candles.Close) > c.ATRLength {
+		m.atr = talib.Atr(candles.High, candles.Low, candles.Close, c.ATRLength)
+	}
+	return m
+}
+
+// fill возвращает цену исполнения заявки direction (1 - покупка, -1 - продажа)
+// на qty по price на баре i и комиссию за него. maker - исполнение лимитной заявкой.
+func (m *costModel) fill(direction float64, i int, price, qty float64, maker bool) (execPrice, fee float64) {
+	adverse := price * (m.SpreadBps/2 + m.SlippageBps) / 10000
+	// на прогреве ATR проскальзывание по ATR не начисляется
+	if m.atr != nil && i >= m.ATRLength {
+		adverse += m.SlippageATR * m.atr[i]
+	}
+	if m.SlippageRange > 0 {
+		adverse += m.SlippageRange * (m.candles.High[i] - m.candles.Low[i])
+	}
+	execPrice = price + direction*adverse
+
+	feePercent := m.TakerFeePercent
+	if maker {
+		feePercent = m.MakerFeePercent
+	}
+	return execPrice, qty*math.Abs(execPrice)*feePercent/100 + m.FixedFee
+}
@@ -0,0 +1,195 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/markcheno/go-quote"
+	"github.com/markcheno/go-talib"
+)
+
+// ExitReason - причина закрытия сделки.
+type ExitReason string
+
+const (
+	ExitSignal       ExitReason = "signal"       // противоположный сигнал стратегии
+	ExitStopLoss     ExitReason = "stopLoss"     // фиксированный стоп
+	ExitTakeProfit   ExitReason = "takeProfit"   // цель
+	ExitTrailingStop ExitReason = "trailingStop" // скользящий стоп
+	ExitTime         ExitReason = "timeExit"     // превышено MaxBars баров в сделке
+	ExitEndOfData    ExitReason = "endOfData"    // закрытие в конце данных
+)
+
+// IntrabarPriority - что считать исполненным первым, если бар задел и стоп, и цель.
+// По OHLC порядок движения внутри бара неизвестен.
+type IntrabarPriority string
+
+const (
+	PriorityStop   IntrabarPriority = "stop"   // сначала стоп - пессимистичная оценка
+	PriorityTarget IntrabarPriority = "target" // сначала цель - оптимистичная оценка
+	PriorityOpen   IntrabarPriority = "open"   // сначала уровень, ближайший к open бара
+)
+
+// ExitConfig - защитные выходы, проверяемые по high/low каждого бара после бара входа.
+// Нулевое значение выключает выход. Из процентного и ATR-уровня берётся ближайший к входу.
+type ExitConfig struct {
+	StopLossPercent   float64 `yaml:"stop_loss_percent" json:"stopLossPercent"`     // % от цены входа
+	TakeProfitPercent float64 `yaml:"take_profit_percent" json:"takeProfitPercent"` // % от цены входа
+	StopLossATR       float64 `yaml:"stop_loss_atr" json:"stopLossAtr"`             // ATR на входе
+	TakeProfitATR     float64 `yaml:"take_profit_atr" json:"takeProfitAtr"`         // ATR на входе
+	TrailingPercent   float64 `yaml:"trailing_percent" json:"trailingPercent"`      // % от лучшей цены в сделке
+	TrailingATR       float64 `yaml:"trailing_atr" json:"trailingAtr"`              // ATR на входе от лучшей цены
+	ATRLength         int     `yaml:"atr_length" json:"atrLength"`
+	MaxBars           int     `yaml:"max_bars" json:"maxBars"` // закрытие по close через MaxBars баров
+
+	IntrabarPriority IntrabarPriority `yaml:"intrabar_priority" json:"intrabarPriority"`
+}
+
+func (c ExitConfig) Validate() error {
+	usesATR := false
+	for _, v := range []struct {
+		name  string
+		value float64
+		atr   bool
+	}{
+		{"stopLossPercent", c.StopLossPercent, false},
+		{"takeProfitPercent", c.TakeProfitPercent, false},
+		{"stopLossAtr", c.StopLossATR, true},
+		{"takeProfitAtr", c.TakeProfitATR, true},
+		{"trailingPercent", c.TrailingPercent, false},
+		{"trailingAtr", c.TrailingATR, true},
+	} {
+		if v.value < 0 || math.IsNaN(v.value) {
+			return fmt.Errorf("backtest.exits.%s must not be negative, got %v", v.name, v.value)
+		}
+		usesATR = usesATR || v.atr && v.value > 0
+	}
+	if c.StopLossPercent >= 100 || c.TrailingPercent >= 100 {
+		return fmt.Errorf("backtest.exits: stop and trailing percent must be below 100")
+	}
+	if usesATR && c.ATRLength < 1 {
+		return fmt.Errorf("backtest.exits.atrLength must be at least 1 with ATR exits, got %d", c.ATRLength)
+	}
+	if c.MaxBars < 0 {
+		return fmt.Errorf("backtest.exits.maxBars must not be negative, got %d", c.MaxBars)
+	}
+	switch c.IntrabarPriority {
+	case "", PriorityStop, PriorityTarget, PriorityOpen:
+		return nil
+	}
+	return fmt.Errorf("backtest.exits.intrabarPriority must be stop, target or open, got %q", c.IntrabarPriority)
+}
+
+// exitModel выставляет уровни позициям и проверяет их по барам котировки.
+type exitModel struct {
+	ExitConfig
+	candles quote.Quote
+	atr     []float64
+}
+
+func newExitModel(c ExitConfig, candles quote.Quote) *exitModel {
+	m := &exitModel{ExitConfig: c, candles: candles}
+	if (c.StopLossATR > 0 || c.TakeProfitATR > 0 || c.TrailingATR > 0) && len(candles.Close) > c.ATRLength {
+		m.atr = talib.Atr(candles.High, candles.Low, candles.Close, c.ATRLength)
+	}
+	return m
+}
+
+// arm выставляет уровни позиции, открытой по price на баре i.
+// На прогреве ATR уровни по ATR не выставляются.
+func (m *exitModel) arm(pos *position, i int, price float64) {
+	atr := 0.0
+	if m.atr != nil && i >= m.ATRLength {
+		atr = m.atr[i]
+	}
+	nearest := func(percent, atrs float64) float64 {
+		d := math.Inf(1)
+		if percent > 0 {
+			d = price * percent / 100
+		}
+		if atrs > 0 && atr > 0 {
+			d = math.Min(d, atrs*atr)
+		}
+		if math.IsInf(d, 1) {
+			return 0
+		}
+		return d
+	}
+	if d := nearest(m.StopLossPercent, m.StopLossATR); d > 0 {
+		pos.stop = price - pos.side*d
+	}
+	if d := nearest(m.TakeProfitPercent, m.TakeProfitATR); d > 0 {
+		pos.target = price + pos.side*d
+	}
+	if m.TrailingATR > 0 && atr > 0 {
+		pos.trailATR = m.TrailingATR * atr
+	}
+	pos.best = price
+}
+
+// check проверяет защитные выходы позиции на баре i и возвращает цену выхода и причину;
+// ok=false - позиция остаётся открытой. maker - выход лимитной заявкой (цель).
+func (m *exitModel) check(pos *position, i int) (price float64, reason ExitReason, maker, ok bool) {
+	open, high, low := m.candles.Open[i], m.candles.High[i], m.candles.Low[i]
+	side := pos.side
+
+	// стоп: ближайший к цене из фиксированного и скользящего
+	stop, stopReason := pos.stop, ExitStopLoss
+	if trail := m.trailingStop(pos); trail != 0 && (stop == 0 || side*(trail-stop) > 0) {
+		stop, stopReason = trail, ExitTrailingStop
+	}
+
+	// уровень задет, если цена бара дошла до него; при гэпе за уровень исполнение по open
+	stopHit := stop != 0 && (side > 0 && low <= stop || side < 0 && high >= stop)
+	targetHit := pos.target != 0 && (side > 0 && high >= pos.target || side < 0 && low <= pos.target)
+	stopGap := stopHit && side*(open-stop) <= 0
+	targetGap := targetHit && side*(open-pos.target) >= 0
+
+	stopFirst := true
+	if stopHit && targetHit && !stopGap {
+		switch {
+		case targetGap || m.IntrabarPriority == PriorityTarget:
+			stopFirst = false
+		case m.IntrabarPriority == PriorityOpen:
+			stopFirst = math.Abs(open-stop) <= math.Abs(open-pos.target)
+		}
+	}
+
+	switch {
+	case stopHit && (stopFirst || !targetHit):
+		if stopGap {
+			return open, stopReason, false, true
+		}
+		return stop, stopReason, false, true
+	case targetHit:
+		if targetGap {
+			return open, ExitTakeProfit, true, true
+		}
+		return pos.target, ExitTakeProfit, true, true
+	case m.MaxBars > 0 && i-pos.entryIndex >= m.MaxBars:
+		return m.candles.Close[i], ExitTime, false, true
+	}
+
+	// лучшая цена обновляется после проверки: порядок high и low внутри бара неизвестен
+	if side > 0 {
+		pos.best = math.Max(pos.best, high)
+	} else {
+		pos.best = math.Min(pos.best, low)
+	}
+	return 0, "", false, false
+}
+
+// trailingStop возвращает уровень скользящего стопа от лучшей цены; 0 - выключен.
+func (m *exitModel) trailingStop(pos *position) float64 {
+	d := 0.0
+	if m.TrailingPercent > 0 {
+		d = pos.best * m.TrailingPercent / 100
+	}
+	if pos.trailATR > 0 && (d == 0 || pos.trailATR < d) {
+		d = pos.trailATR
+	}
+	if d == 0 {
+		return 0
+	}
+	return pos.best - pos.side*d
+}
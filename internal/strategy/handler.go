@@ -0,0 +1,439 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"main/internal/app"
+	"main/internal/model"
+	"main/internal/resample"
+	"main/internal/utils"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler отдаёт HTTP-маршруты одной стратегии с префиксом Definition.Name.
+type Handler struct {
+	def          Definition
+	app          *app.App
+	strategy     Strategy
+	currentOpti  OptimizationResult
+	evaluatedOn  uint64 // версия данных App, на которых посчитан currentOpti
+	optimization OptimizationResult
+	live         *liveSession
+
+	// mu защищает состояние стратегии: strategy, результаты расчётов и live.
+	// Котировки App защищены своей блокировкой, её берут после mu, не наоборот.
+	mu sync.Mutex
+}
+
+func NewHandler(app *app.App, def Definition) (*Handler, error) {
+	s, err := def.New()
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{
+		def:      def,
+		app:      app,
+		strategy: s,
+	}, nil
+}
+
+func (h *Handler) Register(router gin.IRouter) {
+	prefix := h.def.Name + "/"
+	router.GET(prefix+"default-data", h.locked(h.GetDefaultData))
+	router.POST(prefix+"update", h.locked(h.UpdateData))
+	router.POST(prefix+"apply-config", h.locked(h.ApplyConfig))
+	router.POST(prefix+"save-config", h.locked(h.SaveConfig))
+	router.GET(prefix+"default-config", h.locked(h.GetDefaultConfig))
+	router.POST(prefix+"optimize", h.locked(h.Optimize))
+	router.POST(prefix+"evaluate", h.locked(h.Evaluate))
+	router.GET(prefix+"trades", h.locked(h.GetTrades))
+	router.GET(prefix+"trades.csv", h.locked(h.GetTradesCSV))
+	router.GET(prefix+"explain", h.locked(h.Explain))
+	router.POST(prefix+"live/start", h.locked(h.StartLive))
+	router.POST(prefix+"live/stop", h.locked(h.StopLive))
+	router.GET(prefix+"live/status", h.locked(h.GetLiveStatus))
+}
+
+// locked выполняет fn под блокировкой стратегии: запросы к разным стратегиям
+// и их live-потоки не ждут друг друга.
+func (h *Handler) locked(fn gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		fn(c)
+	}
+}
+
+func (h *Handler) logPrefix() string {
+	return "[" + strings.ToUpper(h.def.Name) + "]"
+}
+
+// dropStale сбрасывает результат evaluate, если котировки с тех пор перезагрузил
+// update этой или другой стратегии.
+func (h *Handler) dropStale(sel app.Selection) {
+	if h.evaluatedOn != sel.Version {
+		h.currentOpti = OptimizationResult{}
+	}
+}
+
+func (h *Handler) GetDefaultData(c *gin.Context) {
+
+	sel, _, _ := h.app.Current()
+	h.dropStale(sel)
+	intervalQuote := utils.ParsePeriod(string(sel.Interval))
+	chartData, _ := h.app.QuoteFor(sel.Symbol, intervalQuote)
+	c.JSON(http.StatusOK, gin.H{
+		"symbol":           sel.Symbol,
+		"source":           sel.Source,
+		"startDate":        sel.StartDate,
+		"endDate":          sel.EndDate,
+		"interval":         intervalQuote,
+		"config":           h.strategy.Settings(),
+		"currentOpti":      h.currentOpti,
+		"optimization":     h.optimization,
+		"chartData":        chartData,
+		"signalBuyPoints":  h.strategy.BuySignals(),
+		"signalSellPoints": h.strategy.SellSignals(),
+		"series":           h.strategy.Series(),
+		"levels":           h.strategy.Levels(),
+	})
+}
+
+func (h *Handler) UpdateData(c *gin.Context) {
+	// новые данные заменяют котировки, на которых работал live-поток
+	h.stopLive()
+
+	sel, _, _ := h.app.Current()
+	if err := c.ShouldBindJSON(&sel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sel.Interval = utils.ParsePeriod(sel.IntervalString)
+
+	q, report, err := h.app.LoadQuote(c.Request.Context(), sel.Source, sel.Symbol, sel.StartDate, sel.EndDate, sel.Interval)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.app.Select(sel)
+	// сделки прошлого evaluate посчитаны на других котировках
+	h.currentOpti = OptimizationResult{}
+
+	if err := h.prepare(c.Request.Context(), sel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.strategy.Execute(q, true)
+
+	c.JSON(http.StatusOK, gin.H{
+		"symbol":           sel.Symbol,
+		"source":           sel.Source,
+		"startDate":        sel.StartDate,
+		"endDate":          sel.EndDate,
+		"interval":         sel.Interval,
+		"config":           h.strategy.Settings(),
+		"currentOpti":      h.currentOpti,
+		"optimization":     h.optimization,
+		"chartData":        q,
+		"quality":          report,
+		"signalBuyPoints":  h.strategy.BuySignals(),
+		"signalSellPoints": h.strategy.SellSignals(),
+		"series":           h.strategy.Series(),
+		"levels":           h.strategy.Levels(),
+	})
+}
+
+func (h *Handler) ApplyConfig(c *gin.Context) {
+	sel, q, ok := h.app.Current()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no quote data for symbol/interval"})
+		return
+	}
+	// конфигурация, несовместимая с загруженными данными, не применяется
+	prepare := func() error { return h.prepare(c.Request.Context(), sel) }
+	if err := h.bindSettings(c, prepare); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	// сделки прошлого evaluate посчитаны с прежней конфигурацией
+	h.currentOpti = OptimizationResult{}
+
+	h.strategy.Execute(q, true)
+
+	c.JSON(http.StatusOK, gin.H{
+		"chartData":        q,
+		"signalBuyPoints":  h.strategy.BuySignals(),
+		"signalSellPoints": h.strategy.SellSignals(),
+		"series":           h.strategy.Series(),
+		"levels":           h.strategy.Levels(),
+	})
+}
+
+func (h *Handler) SaveConfig(c *gin.Context) {
+	if err := h.bindSettings(c, nil); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := h.def.Store.Save(h.strategy.Settings()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// prepare проверяет старший период MultiTimeframe-стратегии и загружает его бары,
+// если их нельзя собрать из баров выбранного интервала.
+func (h *Handler) prepare(ctx context.Context, sel app.Selection) error {
+	mtf, ok := h.strategy.(MultiTimeframe)
+	if !ok {
+		return nil
+	}
+	period, enabled := mtf.HigherPeriod()
+	if !enabled {
+		return nil
+	}
+	base := utils.PeriodDuration(sel.Interval)
+	if utils.PeriodDuration(period) <= base {
+		return fmt.Errorf("higher timeframe %s must be longer than the data period %s", period, base)
+	}
+	if resample.CanResample(base, period) {
+		return nil
+	}
+	q, _, err := h.app.LoadQuote(ctx, sel.Source, sel.Symbol, sel.StartDate, sel.EndDate, period)
+	if err != nil {
+		return fmt.Errorf("higher timeframe %s: %w", period, err)
+	}
+	mtf.SetHigherQuote(q)
+	return nil
+}
+
+// bindSettings обновляет конфигурацию стратегии из тела запроса.
+// Если тело не разбирается или конфигурация не проходит Validate и check, прежняя восстанавливается.
+func (h *Handler) bindSettings(c *gin.Context, check func() error) error {
+	settings := h.strategy.Settings()
+	previous, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	err = c.ShouldBindJSON(settings)
+	if v, ok := settings.(Validator); ok && err == nil {
+		err = v.Validate()
+	}
+	if check != nil && err == nil {
+		err = check()
+	}
+	if err != nil {
+		if restoreErr := json.Unmarshal(previous, settings); restoreErr != nil {
+			return restoreErr
+		}
+		return err
+	}
+	return nil
+}
+
+// errorResponse формирует тело ответа с ошибкой. Для ошибок с позицией
+// (разбор правил) добавляются line и column, чтобы UI мог подсветить место.
+func errorResponse(err error) gin.H {
+	resp := gin.H{"error": err.Error()}
+	var positioned interface{ Position() (line, column int) }
+	if errors.As(err, &positioned) {
+		resp["line"], resp["column"] = positioned.Position()
+	}
+	return resp
+}
+
+// GetDefaultConfig заново читает конфигурацию из файла и пересоздаёт стратегию.
+func (h *Handler) GetDefaultConfig(c *gin.Context) {
+	s, err := h.def.New()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.strategy = s
+	h.currentOpti = OptimizationResult{}
+
+	c.JSON(http.StatusOK, gin.H{
+		"config": h.strategy.Settings(),
+	})
+}
+
+func (h *Handler) Optimize(c *gin.Context) {
+	sel, q, ok := h.app.Current()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no quote data for symbol/interval"})
+		return
+	}
+	if err := h.prepare(c.Request.Context(), sel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	optimizationResult, best := Optimize(h.strategy, q)
+	if best == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no configurations to optimize"})
+		return
+	}
+	h.strategy = best
+	h.optimization = optimizationResult
+	h.currentOpti = OptimizationResult{}
+	h.strategy.Execute(q, true)
+
+	c.JSON(http.StatusOK, gin.H{
+		"config":           h.strategy.Settings(),
+		"optimization":     optimizationResult,
+		"chartData":        q,
+		"signalBuyPoints":  h.strategy.BuySignals(),
+		"signalSellPoints": h.strategy.SellSignals(),
+		"series":           h.strategy.Series(),
+		"levels":           h.strategy.Levels(),
+	})
+}
+
+func (h *Handler) Evaluate(c *gin.Context) {
+	sel, q, ok := h.app.Current()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no quote data for symbol/interval"})
+		return
+	}
+	if err := h.prepare(c.Request.Context(), sel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.currentOpti = Evaluate(h.strategy, q)
+	h.evaluatedOn = sel.Version
+
+	c.JSON(http.StatusOK, gin.H{
+		"currentOpti": h.currentOpti,
+		"trades":      h.currentOpti.Ledger,
+	})
+}
+
+// GetTrades отдаёт сделки последнего evaluate файлом JSON.
+func (h *Handler) GetTrades(c *gin.Context) {
+	sel, _, _ := h.app.Current()
+	h.dropStale(sel)
+	if h.currentOpti.Ledger == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no trades, run evaluate first"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-trades.json"`, h.def.Name))
+	c.JSON(http.StatusOK, h.currentOpti.Ledger)
+}
+
+// GetTradesCSV отдаёт сделки последнего evaluate файлом CSV.
+func (h *Handler) GetTradesCSV(c *gin.Context) {
+	sel, _, _ := h.app.Current()
+	h.dropStale(sel)
+	if h.currentOpti.Ledger == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no trades, run evaluate first"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-trades.csv"`, h.def.Name))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := writeTradesCSV(c.Writer, h.currentOpti.Ledger); err != nil {
+		log.Printf("%s trades csv: %v", h.logPrefix(), err)
+	}
+}
+
+// Explain возвращает разбор условий на баре, которому принадлежит ?date=,
+// а без date - разбор всех сигналов и почти сработавших баров последнего расчёта.
+func (h *Handler) Explain(c *gin.Context) {
+	explainer, ok := h.strategy.(Explainer)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("strategy %q does not explain signals", h.def.Name)})
+		return
+	}
+	explanations := explainer.Explanations()
+
+	date := c.Query("date")
+	if date == "" {
+		notable := make([]model.Explanation, 0)
+		for _, e := range explanations {
+			if e.Signal || e.NearMiss {
+				notable = append(notable, e)
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"explanations": notable,
+		})
+		return
+	}
+
+	at, _, err := utils.ParseDateRange(date, "", time.UTC)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	_, q, _ := h.app.Current()
+	bar := sort.Search(len(q.Date), func(i int) bool { return q.Date[i].After(at) }) - 1
+	if bar < 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("no bar at %s", date)})
+		return
+	}
+
+	barExplanations := make([]model.Explanation, 0, 2)
+	for _, e := range explanations {
+		if e.Date.Equal(q.Date[bar]) {
+			barExplanations = append(barExplanations, e)
+		}
+	}
+	if len(barExplanations) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("bar %s was not analyzed (warm-up or no data)", q.Date[bar].Format(time.RFC3339))})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"date":         q.Date[bar],
+		"explanations": barExplanations,
+	})
+}
+
+// Handlers - обработчики всех зарегистрированных стратегий.
+type Handlers []*Handler
+
+// NewHandlers создаёт обработчики для всех стратегий из реестра.
+func NewHandlers(app *app.App) (Handlers, error) {
+	var handlers Handlers
+	for _, def := range Definitions() {
+		h, err := NewHandler(app, def)
+		if err != nil {
+			return nil, fmt.Errorf("strategy %q: %w", def.Name, err)
+		}
+		handlers = append(handlers, h)
+	}
+	return handlers, nil
+}
+
+func (hs Handlers) Register(router gin.IRouter) {
+	for _, h := range hs {
+		h.Register(router)
+	}
+	router.GET("strategies", hs.GetStrategies)
+}
+
+type strategyInfo struct {
+	Name   string `json:"name"`
+	Config any    `json:"config"`
+}
+
+func (hs Handlers) GetStrategies(c *gin.Context) {
+	infos := make([]strategyInfo, 0, len(hs))
+	for _, h := range hs {
+		h.mu.Lock()
+		infos = append(infos, strategyInfo{Name: h.def.Name, Config: h.strategy.Settings()})
+		h.mu.Unlock()
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"strategies": infos,
+	})
+}
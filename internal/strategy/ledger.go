@@ -0,0 +1,44 @@
+package strategy
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var tradeCSVHeader = []string{
+	"side", "entry_time", "exit_time", "entry_price", "exit_price", "quantity",
+	"profit", "profit_percent", "costs", "bars", "mae_percent", "mfe_percent", "exit_reason",
+}
+
+// writeTradesCSV пишет сделки в CSV с заголовком; время в RFC 3339, числа без округления.
+func writeTradesCSV(w io.Writer, trades []Trade) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(tradeCSVHeader); err != nil {
+		return err
+	}
+	num := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	for _, t := range trades {
+		record := []string{
+			t.Side,
+			t.EntryTime.Format(time.RFC3339),
+			t.ExitTime.Format(time.RFC3339),
+			num(t.EntryPrice),
+			num(t.ExitPrice),
+			num(t.Quantity),
+			num(t.Profit),
+			num(t.ProfitPercent),
+			num(t.Costs),
+			strconv.Itoa(t.Bars),
+			num(t.MAEPercent),
+			num(t.MFEPercent),
+			string(t.ExitReason),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
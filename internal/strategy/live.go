@@ -0,0 +1,181 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"main/internal/feeder"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/markcheno/go-quote"
+)
+
+// maxLiveEvents ограничивает историю сигналов, накопленных в live-режиме.
+const maxLiveEvents = 100
+
+type liveEvent struct {
+	Date  time.Time `json:"date"`
+	Type  string    `json:"type"` // buy или sell
+	Price float64   `json:"price"`
+}
+
+// liveSession - состояние потока свечей, на каждом закрытом баре пересчитывается стратегия.
+type liveSession struct {
+	cancel context.CancelFunc
+
+	Running          bool           `json:"running"`
+	Mode             string         `json:"mode"`
+	Source           string         `json:"source"`
+	Symbol           string         `json:"symbol"`
+	Interval         quote.Period   `json:"interval"`
+	StartedAt        time.Time      `json:"startedAt"`
+	LastCandle       *feeder.Candle `json:"lastCandle"`
+	SignalBuyOnLast  bool           `json:"signalBuyOnLast"`
+	SignalSellOnLast bool           `json:"signalSellOnLast"`
+	Events           []liveEvent    `json:"events"`
+	Error            string         `json:"error,omitempty"`
+}
+
+type liveRequest struct {
+	Mode          string `json:"mode"`          // poll или replay
+	PollSeconds   int    `json:"pollSeconds"`   // poll: период опроса
+	ReplayEnd     string `json:"replayEnd"`     // replay: до какой даты проигрывать
+	ReplayDelayMs int    `json:"replayDelayMs"` // replay: пауза между барами
+}
+
+// StartLive запускает поток свечей для текущих symbol/interval, загруженных через <name>/update.
+func (h *Handler) StartLive(c *gin.Context) {
+	req := liveRequest{Mode: "poll", PollSeconds: 30, ReplayDelayMs: 1000}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sel, q, ok := h.app.Current()
+	if !ok || len(q.Date) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no quote data for symbol/interval"})
+		return
+	}
+	src, err := h.app.Feeders.Get(sel.Source)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var streamer feeder.Streamer
+	switch req.Mode {
+	case "poll":
+		if req.PollSeconds <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "pollSeconds must be positive"})
+			return
+		}
+		streamer = feeder.NewStreamPolling(src.Feeder, time.Duration(req.PollSeconds)*time.Second)
+	case "replay":
+		if req.ReplayEnd == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "replayEnd is required for replay mode"})
+			return
+		}
+		streamer = feeder.NewStreamReplay(src.Feeder, "", req.ReplayEnd, time.Duration(req.ReplayDelayMs)*time.Millisecond)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown live mode %q", req.Mode)})
+		return
+	}
+
+	h.stopLive()
+	ctx, cancel := context.WithCancel(context.Background())
+	h.live = &liveSession{
+		cancel:    cancel,
+		Running:   true,
+		Mode:      req.Mode,
+		Source:    src.Name,
+		Symbol:    sel.Symbol,
+		Interval:  sel.Interval,
+		StartedAt: time.Now(),
+		Events:    []liveEvent{},
+	}
+	go h.runLive(ctx, h.live, streamer, q.Date[len(q.Date)-1])
+
+	c.JSON(http.StatusOK, gin.H{"live": h.live})
+}
+
+func (h *Handler) StopLive(c *gin.Context) {
+	h.stopLive()
+	c.JSON(http.StatusOK, gin.H{"live": h.live})
+}
+
+func (h *Handler) GetLiveStatus(c *gin.Context) {
+	if h.live == nil {
+		c.JSON(http.StatusOK, gin.H{"live": nil})
+		return
+	}
+	chartData, _ := h.app.QuoteFor(h.live.Symbol, h.live.Interval)
+	c.JSON(http.StatusOK, gin.H{
+		"live":             h.live,
+		"chartData":        chartData,
+		"signalBuyPoints":  h.strategy.BuySignals(),
+		"signalSellPoints": h.strategy.SellSignals(),
+		"series":           h.strategy.Series(),
+		"levels":           h.strategy.Levels(),
+	})
+}
+
+// stopLive останавливает текущий поток. Вызывается под h.mu.
+func (h *Handler) stopLive() {
+	if h.live != nil && h.live.Running {
+		h.live.cancel()
+		h.live.Running = false
+	}
+}
+
+func (h *Handler) runLive(ctx context.Context, session *liveSession, streamer feeder.Streamer, since time.Time) {
+	candles := make(chan feeder.Candle)
+	done := make(chan error, 1)
+	go func() {
+		done <- streamer.Stream(ctx, session.Symbol, session.Interval, since, candles)
+		close(candles)
+	}()
+
+	for candle := range candles {
+		h.mu.Lock()
+		if session.Running {
+			h.applyCandle(session, candle)
+		}
+		h.mu.Unlock()
+	}
+
+	err := <-done
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err != nil && ctx.Err() == nil {
+		log.Printf("%s live %s %s stopped: %v", h.logPrefix(), session.Symbol, session.Interval, err)
+		session.Error = err.Error()
+	}
+	session.Running = false
+}
+
+// applyCandle добавляет свечу в котировки и на закрытом баре пересчитывает сигналы.
+func (h *Handler) applyCandle(session *liveSession, candle feeder.Candle) {
+	q := h.app.AppendCandle(candle)
+	session.LastCandle = &candle
+	if !candle.Closed {
+		return
+	}
+
+	buy, sell := h.strategy.Execute(q, true)
+	session.SignalBuyOnLast, session.SignalSellOnLast = buy, sell
+	if buy {
+		session.addEvent(liveEvent{Date: candle.Date, Type: "buy", Price: candle.Close})
+	}
+	if sell {
+		session.addEvent(liveEvent{Date: candle.Date, Type: "sell", Price: candle.Close})
+	}
+}
+
+func (s *liveSession) addEvent(e liveEvent) {
+	s.Events = append(s.Events, e)
+	if len(s.Events) > maxLiveEvents {
+		s.Events = s.Events[len(s.Events)-maxLiveEvents:]
+	}
+}
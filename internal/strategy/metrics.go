@@ -0,0 +1,206 @@
+package strategy
+
+import (
+	"fmt"
+	"main/internal/resample"
+	"math"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+const year = 365.25 * 24 * time.Hour
+
+// Metrics - показатели доходности с поправкой на риск. Коэффициенты, которые
+// не определены (нет убытков, нулевая волатильность, нет просадки), равны 0;
+// оптимизатор сравнивает варианты по Objective.rank.
+type Metrics struct {
+	Sharpe               float64 `json:"sharpe"`  // по доходностям капитала за бар, в годовом выражении, без безрисковой ставки
+	Sortino              float64 `json:"sortino"` // как Sharpe, но риск - только отрицательные доходности
+	Calmar               float64 `json:"calmar"`  // CAGR / максимальная просадка в %
+	ProfitFactor         float64 `json:"profitFactor"`
+	Expectancy           float64 `json:"expectancy"` // средний результат сделки
+	AverageWin           float64 `json:"averageWin"`
+	AverageLoss          float64 `json:"averageLoss"` // положительное число
+	MaxConsecutiveLosses int     `json:"maxConsecutiveLosses"`
+	ExposurePercent      float64 `json:"exposurePercent"` // доля баров с открытой позицией
+	MaxDrawdownPercent   float64 `json:"maxDrawdownPercent"`
+	MaxDrawdownBars      int     `json:"maxDrawdownBars"` // самая долгая просадка от пика до восстановления или конца данных
+	CAGR                 float64 `json:"cagr"`            // среднегодовой рост капитала, %
+	RecoveryFactor       float64 `json:"recoveryFactor"`  // рост капитала с учётом открытых позиций / максимальная просадка
+}
+
+// computeMetrics считает Metrics по капиталу на закрытии баров candles и сделкам.
+// exposedBars - число баров, на закрытии которых была открыта позиция, drawdown -
+// максимальная просадка equity в валюте счёта.
+func computeMetrics(candles quote.Quote, capital float64, equity []float64, trades []Trade, exposedBars int, drawdown float64) Metrics {
+	var m Metrics
+	n := len(equity)
+	if n == 0 {
+		return m
+	}
+
+	// доходности за бар
+	var sum, sumSq, downSq float64
+	returns := 0
+	prev := capital
+	for _, eq := range equity {
+		if prev > 0 {
+			r := eq/prev - 1
+			sum += r
+			sumSq += r * r
+			if r < 0 {
+				downSq += r * r
+			}
+			returns++
+		}
+		prev = eq
+	}
+	period := resample.DetectPeriod(candles)
+	if returns > 1 && period > 0 {
+		annual := math.Sqrt(float64(year) / float64(period))
+		mean := sum / float64(returns)
+		if std := math.Sqrt(sumSq/float64(returns) - mean*mean); std > 0 {
+			m.Sharpe = mean / std * annual
+		}
+		if down := math.Sqrt(downSq / float64(returns)); down > 0 {
+			m.Sortino = mean / down * annual
+		}
+	}
+
+	// просадка в % и её длительность
+	peak, peakIndex := capital, -1
+	for i, eq := range equity {
+		if eq >= peak {
+			peak, peakIndex = eq, i
+			continue
+		}
+		if peak > 0 {
+			m.MaxDrawdownPercent = math.Max(m.MaxDrawdownPercent, (peak-eq)/peak*100)
+		}
+		m.MaxDrawdownBars = max(m.MaxDrawdownBars, i-peakIndex)
+	}
+
+	// CAGR за время от открытия первого бара до закрытия последнего
+	if span := candles.Date[n-1].Sub(candles.Date[0]) + period; span > 0 && capital > 0 {
+		m.CAGR = -100
+		if final := equity[n-1]; final > 0 {
+			m.CAGR = (math.Pow(final/capital, float64(year)/float64(span)) - 1) * 100
+		}
+	}
+	if m.MaxDrawdownPercent > 0 {
+		m.Calmar = m.CAGR / m.MaxDrawdownPercent
+	}
+	// просадка считается по капиталу с открытыми позициями, поэтому и прибыль берётся по нему
+	if drawdown > 0 {
+		m.RecoveryFactor = (equity[n-1] - capital) / drawdown
+	}
+	m.ExposurePercent = float64(exposedBars) / float64(n) * 100
+
+	// сделки
+	var grossWin, grossLoss float64
+	var wins, losses, streak int
+	for _, t := range trades {
+		switch {
+		case t.Profit > 0:
+			grossWin += t.Profit
+			wins++
+			streak = 0
+		case t.Profit < 0:
+			grossLoss -= t.Profit
+			losses++
+			streak++
+			m.MaxConsecutiveLosses = max(m.MaxConsecutiveLosses, streak)
+		default:
+			streak = 0
+		}
+	}
+	if len(trades) > 0 {
+		m.Expectancy = (grossWin - grossLoss) / float64(len(trades))
+	}
+	if wins > 0 {
+		m.AverageWin = grossWin / float64(wins)
+	}
+	if losses > 0 {
+		m.AverageLoss = grossLoss / float64(losses)
+	}
+	if grossLoss > 0 {
+		m.ProfitFactor = grossWin / grossLoss
+	}
+
+	m.finite()
+	return m
+}
+
+// finite обнуляет бесконечности и NaN, которые не кодируются в JSON.
+func (m *Metrics) finite() {
+	for _, v := range []*float64{
+		&m.Sharpe, &m.Sortino, &m.Calmar, &m.ProfitFactor, &m.Expectancy, &m.AverageWin,
+		&m.AverageLoss, &m.ExposurePercent, &m.MaxDrawdownPercent, &m.CAGR, &m.RecoveryFactor,
+	} {
+		if math.IsNaN(*v) || math.IsInf(*v, 0) {
+			*v = 0
+		}
+	}
+}
+
+// Objective - показатель, который максимизирует оптимизатор.
+type Objective string
+
+const (
+	ObjectiveProfit         Objective = "profit"
+	ObjectiveSharpe         Objective = "sharpe"
+	ObjectiveSortino        Objective = "sortino"
+	ObjectiveCalmar         Objective = "calmar"
+	ObjectiveProfitFactor   Objective = "profitFactor"
+	ObjectiveExpectancy     Objective = "expectancy"
+	ObjectiveRecoveryFactor Objective = "recoveryFactor"
+	ObjectiveCAGR           Objective = "cagr"
+)
+
+var objectives = map[Objective]func(r OptimizationResult) float64{
+	ObjectiveProfit:         func(r OptimizationResult) float64 { return r.Profit },
+	ObjectiveSharpe:         func(r OptimizationResult) float64 { return r.Metrics.Sharpe },
+	ObjectiveSortino:        func(r OptimizationResult) float64 { return r.Metrics.Sortino },
+	ObjectiveCalmar:         func(r OptimizationResult) float64 { return r.Metrics.Calmar },
+	ObjectiveProfitFactor:   func(r OptimizationResult) float64 { return r.Metrics.ProfitFactor },
+	ObjectiveExpectancy:     func(r OptimizationResult) float64 { return r.Metrics.Expectancy },
+	ObjectiveRecoveryFactor: func(r OptimizationResult) float64 { return r.Metrics.RecoveryFactor },
+	ObjectiveCAGR:           func(r OptimizationResult) float64 { return r.Metrics.CAGR },
+}
+
+func (o Objective) Validate() error {
+	if _, ok := objectives[o]; ok || o == "" {
+		return nil
+	}
+	return fmt.Errorf("backtest.objective must be one of profit, sharpe, sortino, calmar, profitFactor, expectancy, recoveryFactor, cagr, got %q", o)
+}
+
+// score возвращает значение показателя o для результата; пустой o - прибыль.
+func (o Objective) score(r OptimizationResult) float64 {
+	if f, ok := objectives[o]; ok {
+		return f(r)
+	}
+	return r.Profit
+}
+
+// rank возвращает score для сравнения вариантов. Profit factor, Calmar и recovery factor
+// без убытков или просадки не определены, а в Metrics равны 0; если при этом есть
+// прибыль, вариант считается лучше любого с конечным значением.
+func (o Objective) rank(r OptimizationResult) float64 {
+	switch o {
+	case ObjectiveProfitFactor:
+		if r.Metrics.AverageLoss == 0 && r.Metrics.AverageWin > 0 {
+			return math.MaxFloat64
+		}
+	case ObjectiveCalmar:
+		if r.Metrics.MaxDrawdownPercent == 0 && r.Metrics.CAGR > 0 {
+			return math.MaxFloat64
+		}
+	case ObjectiveRecoveryFactor:
+		if r.Drawdown == 0 && r.FinalEquity > r.InitialCapital {
+			return math.MaxFloat64
+		}
+	}
+	return o.score(r)
+}
@@ -0,0 +1,82 @@
+package strategy
+
+import "testing"
+
+func TestRecoveryFactorUsesEquityCurve(t *testing.T) {
+	// позиция ещё открыта: закрытых сделок нет, но капитал вырос на 200 после просадки 100
+	candles := flat(100, 99, 102)
+	m := computeMetrics(candles, 10000, []float64{10000, 9900, 10200}, nil, 3, 100)
+	if !near(m.RecoveryFactor, 2) {
+		t.Errorf("recovery factor = %v, want 2", m.RecoveryFactor)
+	}
+	if !near(m.MaxDrawdownPercent, 1) || m.MaxDrawdownBars != 1 {
+		t.Errorf("drawdown = %v%% over %d bars, want 1%% over 1 bar", m.MaxDrawdownPercent, m.MaxDrawdownBars)
+	}
+}
+
+func TestProfitFactor(t *testing.T) {
+	trades := []Trade{{Profit: 30}, {Profit: -10}, {Profit: -5}, {Profit: 10}}
+	m := computeMetrics(flat(1, 1), 100, []float64{100, 125}, trades, 0, 0)
+	if !near(m.ProfitFactor, 40.0/15) || !near(m.Expectancy, 25.0/4) {
+		t.Errorf("profit factor = %v, expectancy = %v", m.ProfitFactor, m.Expectancy)
+	}
+	if m.MaxConsecutiveLosses != 2 || !near(m.AverageWin, 20) || !near(m.AverageLoss, 7.5) {
+		t.Errorf("metrics = %+v", m)
+	}
+}
+
+func TestRankUndefinedRatios(t *testing.T) {
+	tests := []struct {
+		objective Objective
+		// по убыванию места: неопределённый коэффициент с прибылью, конечный, без сделок
+		undefined, finite, empty OptimizationResult
+	}{
+		{
+			ObjectiveProfitFactor,
+			OptimizationResult{Profit: 10, Metrics: Metrics{AverageWin: 10}},
+			OptimizationResult{Profit: 50, Metrics: Metrics{ProfitFactor: 6, AverageWin: 30, AverageLoss: 10}},
+			OptimizationResult{},
+		},
+		{
+			ObjectiveCalmar,
+			OptimizationResult{Profit: 10, Metrics: Metrics{CAGR: 5}},
+			OptimizationResult{Profit: 50, Metrics: Metrics{CAGR: 30, MaxDrawdownPercent: 10, Calmar: 3}},
+			OptimizationResult{},
+		},
+		{
+			ObjectiveRecoveryFactor,
+			OptimizationResult{InitialCapital: 100, FinalEquity: 110, Profit: 10},
+			OptimizationResult{InitialCapital: 100, FinalEquity: 150, Profit: 50, Drawdown: 10, Metrics: Metrics{RecoveryFactor: 5}},
+			OptimizationResult{InitialCapital: 100, FinalEquity: 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.objective), func(t *testing.T) {
+			undefined, finite, empty := tt.objective.rank(tt.undefined), tt.objective.rank(tt.finite), tt.objective.rank(tt.empty)
+			if !(undefined > finite && finite > empty) {
+				t.Errorf("rank undefined = %v, finite = %v, empty = %v", undefined, finite, empty)
+			}
+			// в JSON неопределённый коэффициент остаётся нулём
+			if score := tt.objective.score(tt.undefined); score != 0 {
+				t.Errorf("score = %v, want 0", score)
+			}
+		})
+	}
+}
+
+func TestOptimizePrefersVariantWithoutLosses(t *testing.T) {
+	cfg := DefaultBacktestConfig()
+	cfg.Objective = ObjectiveProfitFactor
+	// один выигрыш 10 против выигрыша 10 и проигрыша 5 (profit factor 2)
+	allWins := &signalStrategy{cfg: cfg, buys: []int{0}, sells: []int{1}}
+	mixed := &signalStrategy{cfg: cfg, buys: []int{0, 2}, sells: []int{1, 3}}
+	base := &signalStrategy{variants: []*signalStrategy{mixed, allWins}}
+
+	best, strat := Optimize(base, flat(100, 110, 80, 75))
+	if strat != allWins {
+		t.Fatalf("picked %+v, want the variant without losses", best)
+	}
+	if best.Score != 0 || best.Metrics.ProfitFactor != 0 {
+		t.Errorf("score = %v, profit factor = %v, want 0 in JSON", best.Score, best.Metrics.ProfitFactor)
+	}
+}
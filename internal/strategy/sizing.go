@@ -0,0 +1,162 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/markcheno/go-quote"
+	"github.com/markcheno/go-talib"
+)
+
+// SizingPolicy - способ расчёта количества в новой позиции.
+type SizingPolicy string
+
+const (
+	SizeQuantity   SizingPolicy = "quantity"   // фиксированное количество
+	SizeNotional   SizingPolicy = "notional"   // фиксированная сумма в валюте счёта
+	SizePercent    SizingPolicy = "percent"    // процент капитала
+	SizeVolatility SizingPolicy = "volatility" // риск RiskPercent капитала на ATRMultiple ATR
+	SizeKelly      SizingPolicy = "kelly"      // доля Kelly по закрытым сделкам
+)
+
+// SizingConfig - капитал и размер позиций в бэктесте.
+type SizingConfig struct {
+	InitialCapital   float64      `yaml:"initial_capital" json:"initialCapital"`
+	Policy           SizingPolicy `yaml:"policy" json:"policy"`
+	Quantity         float64      `yaml:"quantity" json:"quantity"`                   // для quantity
+	Notional         float64      `yaml:"notional" json:"notional"`                   // для notional
+	EquityPercent    float64      `yaml:"equity_percent" json:"equityPercent"`        // для percent и kelly до первых выигрыша и проигрыша
+	RiskPercent      float64      `yaml:"risk_percent" json:"riskPercent"`            // для volatility
+	ATRLength        int          `yaml:"atr_length" json:"atrLength"`                // для volatility
+	ATRMultiple      float64      `yaml:"atr_multiple" json:"atrMultiple"`            // для volatility
+	KellyFraction    float64      `yaml:"kelly_fraction" json:"kellyFraction"`        // для kelly, 0.5 - половина Kelly
+	MaxOpenPositions int          `yaml:"max_open_positions" json:"maxOpenPositions"` // 0 - без ограничения
+	MaxLeverage      float64      `yaml:"max_leverage" json:"maxLeverage"`            // открытые позиции не дороже MaxLeverage капиталов, 0 - см. leverage
+	Compounding      bool         `yaml:"compounding" json:"compounding"`             // размер от текущего капитала, иначе от начального
+}
+
+func (c SizingConfig) Validate() error {
+	if c.InitialCapital <= 0 {
+		return fmt.Errorf("backtest.sizing.initialCapital must be positive, got %v", c.InitialCapital)
+	}
+	if c.MaxOpenPositions < 0 {
+		return fmt.Errorf("backtest.sizing.maxOpenPositions must not be negative, got %d", c.MaxOpenPositions)
+	}
+	if c.MaxLeverage < 0 || math.IsNaN(c.MaxLeverage) || math.IsInf(c.MaxLeverage, 0) {
+		return fmt.Errorf("backtest.sizing.maxLeverage must be a non-negative number, got %v", c.MaxLeverage)
+	}
+	positive := func(name string, v float64) error {
+		if v <= 0 || math.IsNaN(v) {
+			return fmt.Errorf("backtest.sizing.%s must be positive for policy %s, got %v", name, c.Policy, v)
+		}
+		return nil
+	}
+	switch c.Policy {
+	case SizeQuantity:
+		return positive("quantity", c.Quantity)
+	case SizeNotional:
+		return positive("notional", c.Notional)
+	case SizePercent:
+		return positive("equityPercent", c.EquityPercent)
+	case SizeVolatility:
+		if c.ATRLength < 1 {
+			return fmt.Errorf("backtest.sizing.atrLength must be at least 1, got %d", c.ATRLength)
+		}
+		if err := positive("riskPercent", c.RiskPercent); err != nil {
+			return err
+		}
+		return positive("atrMultiple", c.ATRMultiple)
+	case SizeKelly:
+		if err := positive("equityPercent", c.EquityPercent); err != nil {
+			return err
+		}
+		return positive("kellyFraction", c.KellyFraction)
+	}
+	return fmt.Errorf("backtest.sizing.policy must be quantity, notional, percent, volatility or kelly, got %q", c.Policy)
+}
+
+// leverage возвращает действующий предел стоимости открытых позиций в капиталах.
+// При MaxLeverage = 0 фиксированные размеры не ограничены, как одна единица в прежнем
+// бэктесте, а размеры от капитала ограничены одним капиталом; +Inf - без ограничения.
+func (c SizingConfig) leverage() float64 {
+	switch {
+	case c.MaxLeverage > 0:
+		return c.MaxLeverage
+	case c.Policy == SizeQuantity || c.Policy == SizeNotional:
+		return math.Inf(1)
+	}
+	return 1
+}
+
+// sizer считает количество для новых позиций и ведёт статистику сделок для Kelly.
+type sizer struct {
+	SizingConfig
+	atr []float64
+
+	wins, losses    int
+	winSum, lossSum float64
+}
+
+func newSizer(c SizingConfig, candles quote.Quote) *sizer {
+	z := &sizer{SizingConfig: c}
+	if c.Policy == SizeVolatility && len(candles.Close) > c.ATRLength {
+		z.atr = talib.Atr(candles.High, candles.Low, candles.Close, c.ATRLength)
+	}
+	return z
+}
+
+// record учитывает результат закрытой сделки.
+func (z *sizer) record(pnl float64) {
+	if pnl > 0 {
+		z.wins++
+		z.winSum += pnl
+	} else if pnl < 0 {
+		z.losses++
+		z.lossSum -= pnl
+	}
+}
+
+// quantity возвращает количество для входа по price на баре i при капитале equity;
+// 0 - позицию не открывать.
+func (z *sizer) quantity(i int, price, equity float64) float64 {
+	base := z.InitialCapital
+	if z.Compounding {
+		base = equity
+	}
+	if price <= 0 {
+		return 0
+	}
+
+	switch z.Policy {
+	// фиксированные размеры не зависят от капитала, как одна единица в прежнем бэктесте
+	case SizeQuantity:
+		return z.Quantity
+	case SizeNotional:
+		return z.Notional / price
+	}
+	if equity <= 0 {
+		return 0
+	}
+	switch z.Policy {
+	case SizePercent:
+		return base * z.EquityPercent / 100 / price
+	case SizeVolatility:
+		// до конца прогрева ATR риск оценить нельзя
+		if z.atr == nil || i < z.ATRLength || z.atr[i] <= 0 {
+			return 0
+		}
+		return base * z.RiskPercent / 100 / (z.ATRMultiple * z.atr[i])
+	case SizeKelly:
+		if z.wins == 0 || z.losses == 0 {
+			return base * z.EquityPercent / 100 / price
+		}
+		winRate := float64(z.wins) / float64(z.wins+z.losses)
+		payoff := (z.winSum / float64(z.wins)) / (z.lossSum / float64(z.losses))
+		kelly := winRate - (1-winRate)/payoff
+		if kelly <= 0 {
+			return 0
+		}
+		return base * math.Min(1, z.KellyFraction*kelly) / price
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package strategy
+
+import (
+	"fmt"
+	"main/internal/model"
+	"math"
+	"sort"
+
+	"github.com/markcheno/go-quote"
+)
+
+// Strategy - торговая стратегия, считающая сигналы покупки и продажи по котировкам.
+type Strategy interface {
+	// Settings возвращает указатель на конфигурацию стратегии, в него декодируется JSON из запросов
+	Settings() any
+	// Execute пересчитывает сигналы по candles и сообщает, есть ли сигнал на последнем баре
+	Execute(candles quote.Quote, verbose bool) (signalBuyOnLast, signalSellOnLast bool)
+	BuySignals() []model.IndicatorData
+	SellSignals() []model.IndicatorData
+	// Series возвращает рассчитанные индикаторы, выровненные по барам последнего Execute
+	Series() map[string]model.Series
+	// Levels возвращает пороговые уровни индикаторов для отображения на графике
+	Levels() map[string]float64
+	// Variants перебирает конфигурации для оптимизации, пока yield возвращает true
+	Variants(yield func(Strategy) bool)
+}
+
+// Validator - необязательный интерфейс конфигурации, которую возвращает Settings.
+// Handler проверяет конфигурацию из запроса перед применением и сохранением.
+type Validator interface {
+	Validate() error
+}
+
+// Explainer - необязательный интерфейс стратегии, которая при Execute с verbose
+// сохраняет разбор условий по барам.
+type Explainer interface {
+	Explanations() []model.Explanation
+}
+
+// MultiTimeframe - необязательный интерфейс стратегии, сверяющейся со старшим периодом.
+// Если бары старшего периода нельзя собрать из текущих, Handler загружает их
+// за тот же диапазон дат и передаёт в SetHigherQuote перед Execute.
+type MultiTimeframe interface {
+	HigherPeriod() (period quote.Period, enabled bool)
+	SetHigherQuote(q quote.Quote)
+}
+
+// Definition описывает стратегию для реестра.
+type Definition struct {
+	// Name - имя стратегии, оно же префикс HTTP-маршрутов
+	Name string
+	// New создаёт стратегию с конфигурацией из Store
+	New   func() (Strategy, error)
+	Store ConfigStore
+}
+
+var definitions = map[string]Definition{}
+
+// Register делает стратегию доступной для NewHandler и GET /strategies.
+func Register(def Definition) {
+	if _, exists := definitions[def.Name]; exists {
+		panic(fmt.Sprintf("strategy %q registered twice", def.Name))
+	}
+	definitions[def.Name] = def
+}
+
+// Definitions возвращает зарегистрированные стратегии, упорядоченные по имени.
+func Definitions() []Definition {
+	defs := make([]Definition, 0, len(definitions))
+	for _, def := range definitions {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// Warmup заменяет на NaN первые lookback значений индикатора, которые talib заполняет нулями.
+func Warmup(values []float64, lookback int) []float64 {
+	for i := 0; i < lookback && i < len(values); i++ {
+		values[i] = math.NaN()
+	}
+	return values
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/markcheno/go-quote"
+)
+
+// ParseDateRange переводит строки startDate/endDate в полуинтервал [start, end).
+// Дата без времени в endDate включает весь день. Пустая строка означает отсутствие границы.
+func ParseDateRange(startDate, endDate string, loc *time.Location) (start, end time.Time, err error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if startDate != "" {
+		start, err = parseDate(startDate, loc)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid start date %q: %w", startDate, err)
+		}
+	}
+	if endDate != "" {
+		end, err = parseDate(endDate, loc)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid end date %q: %w", endDate, err)
+		}
+		if len(endDate) == len("2006-01-02") {
+			end = end.AddDate(0, 0, 1)
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		return start, end, fmt.Errorf("start date %s is not before end date %s", startDate, endDate)
+	}
+	return start, end, nil
+}
+
+func parseDate(s string, loc *time.Location) (time.Time, error) {
+	layouts := []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		t, err = time.ParseInLocation(layout, s, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// SliceQuote оставляет бары с датой в полуинтервале [start, end). Нулевая граница не ограничивает.
+func SliceQuote(q quote.Quote, start, end time.Time) quote.Quote {
+	out := quote.NewQuote(q.Symbol, 0)
+	out.Precision = q.Precision
+	for i, d := range q.Date {
+		if !start.IsZero() && d.Before(start) {
+			continue
+		}
+		if !end.IsZero() && !d.Before(end) {
+			continue
+		}
+		AppendBar(&out, q, i)
+	}
+	return out
+}
+
+// SortQuote упорядочивает бары по дате.
+func SortQuote(q quote.Quote) quote.Quote {
+	idx := make([]int, len(q.Date))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return q.Date[idx[a]].Before(q.Date[idx[b]]) })
+
+	out := quote.NewQuote(q.Symbol, 0)
+	out.Precision = q.Precision
+	for _, i := range idx {
+		AppendBar(&out, q, i)
+	}
+	return out
+}
+
+// AppendBar добавляет в dst бар с индексом i из src. Значения, которых нет
+// в коротком массиве src, заменяются NaN.
+func AppendBar(dst *quote.Quote, src quote.Quote, i int) {
+	dst.Date = append(dst.Date, src.Date[i])
+	dst.Open = append(dst.Open, valueAt(src.Open, i))
+	dst.High = append(dst.High, valueAt(src.High, i))
+	dst.Low = append(dst.Low, valueAt(src.Low, i))
+	dst.Close = append(dst.Close, valueAt(src.Close, i))
+	dst.Volume = append(dst.Volume, valueAt(src.Volume, i))
+}
+
+func valueAt(values []float64, i int) float64 {
+	if i >= len(values) {
+		return math.NaN()
+	}
+	return values[i]
+}
+
+// CheckLengths проверяет, что все OHLCV-массивы q одной длины с датами.
+func CheckLengths(q quote.Quote) error {
+	fields := []struct {
+		name string
+		n    int
+	}{
+		{"open", len(q.Open)}, {"high", len(q.High)}, {"low", len(q.Low)},
+		{"close", len(q.Close)}, {"volume", len(q.Volume)},
+	}
+	for _, f := range fields {
+		if f.n != len(q.Date) {
+			return fmt.Errorf("%s has %d values for %d dates", f.name, f.n, len(q.Date))
+		}
+	}
+	return nil
+}
@@ -2,10 +2,18 @@ package utils
 
 import (
 	"strings"
+	"time"
 
 	"github.com/markcheno/go-quote"
 )
 
+// Periods - все периоды go-quote от меньшего к большему.
+var Periods = []quote.Period{
+	quote.Min1, quote.Min3, quote.Min5, quote.Min15, quote.Min30, quote.Min60,
+	quote.Hour2, quote.Hour4, quote.Hour6, quote.Hour8, quote.Hour12,
+	quote.Daily, quote.Day3, quote.Weekly, quote.Monthly,
+}
+
 func ParsePeriod(input string) quote.Period {
 	input = strings.ToLower(input)
 
@@ -44,3 +52,42 @@ func ParsePeriod(input string) quote.Period {
 		return quote.Min60
 	}
 }
+
+// PeriodDuration возвращает номинальную длительность бара.
+// Для месячного периода длительность условная (30 дней).
+func PeriodDuration(p quote.Period) time.Duration {
+	switch p {
+	case quote.Min1:
+		return time.Minute
+	case quote.Min3:
+		return 3 * time.Minute
+	case quote.Min5:
+		return 5 * time.Minute
+	case quote.Min15:
+		return 15 * time.Minute
+	case quote.Min30:
+		return 30 * time.Minute
+	case quote.Min60:
+		return time.Hour
+	case quote.Hour2:
+		return 2 * time.Hour
+	case quote.Hour4:
+		return 4 * time.Hour
+	case quote.Hour6:
+		return 6 * time.Hour
+	case quote.Hour8:
+		return 8 * time.Hour
+	case quote.Hour12:
+		return 12 * time.Hour
+	case quote.Daily:
+		return 24 * time.Hour
+	case quote.Day3:
+		return 3 * 24 * time.Hour
+	case quote.Weekly:
+		return 7 * 24 * time.Hour
+	case quote.Monthly:
+		return 30 * 24 * time.Hour
+	default:
+		return time.Hour
+	}
+}
@@ -5,9 +5,13 @@ import (
 	"log"
 	"main/internal/app"
 	"main/internal/feeder"
-	indicatorrsi "main/internal/indicator/rsi"
+	_ "main/internal/indicator/rsi"
+	_ "main/internal/indicator/rules"
+	_ "main/internal/indicator/sniper"
+	"main/internal/overlay"
+	"main/internal/quality"
+	"main/internal/strategy"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -42,28 +46,24 @@ func main() {
 		c.File("./frontend/dist/index.html")
 	})
 
-	feederType := strings.ToLower(os.Getenv("FEEDER"))
-	if feederType == "" {
-		feederType = "api"
+	feeders, err := feeder.NewRegistryFromEnv()
+	if err != nil {
+		log.Fatalf("Feeder config error: %v", err)
 	}
+	feeders.Register(r)
 
-	var f feeder.Feeder
-
-	if feederType == "api" {
-		f = feeder.NewFeederApiCoinbase()
-	} else if feederType == "json" {
-		f = feeder.NewFeederJSONFile()
-	} else {
-		log.Fatalf("Unknown feeder type: %s", feederType)
+	app := app.NewApp(feeders)
+	if app.QualityPolicy, err = quality.PolicyFromEnv(); err != nil {
+		log.Fatalf("Quote quality config error: %v", err)
 	}
 
-	app := app.NewApp(f)
-
-	trendRSI, err := indicatorrsi.New(app)
+	strategies, err := strategy.NewHandlers(app)
 	if err != nil {
-		panic(fmt.Sprintf("trendRSI error %v", err))
+		panic(fmt.Sprintf("strategy error %v", err))
 	}
-	trendRSI.Register(r)
+	strategies.Register(r)
+
+	overlay.NewHandler(app).Register(r)
 
 	// Запуск сервера
 	fmt.Println("Server starting on :8080")